@@ -0,0 +1,27 @@
+package errs
+
+import "fmt"
+
+const errInvalidEncodingMessage = "Invalid encoding: \"%s\" at position \"%d\""
+
+// InvalidEncodingError is an error type
+// for invalid percent-encoded sequences.
+type InvalidEncodingError struct {
+	sequence string
+	position int
+}
+
+// Error returns the error message text.
+func (err InvalidEncodingError) Error() string {
+	return fmt.Sprintf(errInvalidEncodingMessage,
+		err.sequence,
+		err.position)
+}
+
+// NewErrInvalidEncoding cerate a new error.
+func NewErrInvalidEncoding(position int, sequence string) InvalidEncodingError {
+	return InvalidEncodingError{
+		position: position,
+		sequence: sequence,
+	}
+}
@@ -0,0 +1,19 @@
+package errs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrInvalidEncoding(t *testing.T) {
+	t.Parallel()
+
+	pos := 42
+	sequence := "%zz"
+	require.Equal(t,
+		fmt.Sprintf(errInvalidEncodingMessage, sequence, pos),
+		NewErrInvalidEncoding(pos, sequence).Error(),
+	)
+}
@@ -0,0 +1,30 @@
+package errs
+
+import "fmt"
+
+const errInvalidFieldNameMessage = "Invalid field name: \"%s\" at position \"%d\", %s"
+
+// InvalidFieldNameError is an error
+// type for invalid field names.
+type InvalidFieldNameError struct {
+	name     string
+	reason   string
+	position int
+}
+
+// Error returns the error message text.
+func (err InvalidFieldNameError) Error() string {
+	return fmt.Sprintf(errInvalidFieldNameMessage,
+		err.name,
+		err.position,
+		err.reason)
+}
+
+// NewErrInvalidFieldName cerate a new error.
+func NewErrInvalidFieldName(position int, name, reason string) InvalidFieldNameError {
+	return InvalidFieldNameError{
+		position: position,
+		name:     name,
+		reason:   reason,
+	}
+}
@@ -0,0 +1,20 @@
+package errs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrInvalidFieldName(t *testing.T) {
+	t.Parallel()
+
+	pos := 42
+	name := "$where"
+	reason := "reason"
+	require.Equal(t,
+		fmt.Sprintf(errInvalidFieldNameMessage, name, pos, reason),
+		NewErrInvalidFieldName(pos, name, reason).Error(),
+	)
+}
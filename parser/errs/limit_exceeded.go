@@ -0,0 +1,30 @@
+package errs
+
+import "fmt"
+
+const errLimitExceededMessage = "Limit exceeded: \"%s\" of \"%d\" at position \"%d\""
+
+// LimitExceededError is an error type
+// for queries that exceed a limit.
+type LimitExceededError struct {
+	limit    string
+	max      int
+	position int
+}
+
+// Error returns the error message text.
+func (err LimitExceededError) Error() string {
+	return fmt.Sprintf(errLimitExceededMessage,
+		err.limit,
+		err.max,
+		err.position)
+}
+
+// NewErrLimitExceeded cerate a new error.
+func NewErrLimitExceeded(position int, limit string, max int) LimitExceededError {
+	return LimitExceededError{
+		position: position,
+		limit:    limit,
+		max:      max,
+	}
+}
@@ -0,0 +1,20 @@
+package errs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrLimitExceeded(t *testing.T) {
+	t.Parallel()
+
+	pos := 42
+	limit := "MAX_DEPTH"
+	max := 3
+	require.Equal(t,
+		fmt.Sprintf(errLimitExceededMessage, limit, max, pos),
+		NewErrLimitExceeded(pos, limit, max).Error(),
+	)
+}
@@ -4,20 +4,45 @@ import (
 	"fmt"
 )
 
-const errPolicyViolationMessage = "Policy violation, policy disallow \"%s\""
+const (
+	errPolicyViolationMessage      = "Policy violation, policy disallow \"%s\""
+	errFieldPolicyViolationMessage = "Policy violation, policy disallow \"%s\" with operator \"%s\" at position \"%d\": %s"
+)
 
 // PolicyViolationError is an error
 // type for policy violation.
 type PolicyViolationError struct {
-	key string
+	key      string
+	operator string
+	reason   string
+	position int
 }
 
 // Error returns the error message text.
 func (err PolicyViolationError) Error() string {
-	return fmt.Sprintf(errPolicyViolationMessage, err.key)
+	if err.reason == "" {
+		return fmt.Sprintf(errPolicyViolationMessage, err.key)
+	}
+
+	return fmt.Sprintf(errFieldPolicyViolationMessage,
+		err.key,
+		err.operator,
+		err.position,
+		err.reason)
 }
 
 // NewErrUnexpectedInputEnd cerate a new error.
 func NewErrPolicyViolation(key string) PolicyViolationError {
 	return PolicyViolationError{key: key}
 }
+
+// NewErrFieldPolicyViolation cerate a new error for a
+// field that is used with a disallowed operator or value.
+func NewErrFieldPolicyViolation(position int, key, operator, reason string) PolicyViolationError {
+	return PolicyViolationError{
+		position: position,
+		key:      key,
+		operator: operator,
+		reason:   reason,
+	}
+}
@@ -16,3 +16,16 @@ func TestErrPolicyViolation(t *testing.T) {
 		NewErrPolicyViolation(key).Error(),
 	)
 }
+
+func TestErrFieldPolicyViolation(t *testing.T) {
+	t.Parallel()
+
+	pos := 7
+	key := "status"
+	operator := "=="
+	reason := "value \"x\" is not allowed"
+	require.Equal(t,
+		fmt.Sprintf(errFieldPolicyViolationMessage, key, operator, pos, reason),
+		NewErrFieldPolicyViolation(pos, key, operator, reason).Error(),
+	)
+}
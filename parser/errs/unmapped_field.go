@@ -0,0 +1,27 @@
+package errs
+
+import "fmt"
+
+const errUnmappedFieldMessage = "Unmapped field: \"%s\" at position \"%d\""
+
+// UnmappedFieldError is an error type
+// for fields without mapping.
+type UnmappedFieldError struct {
+	name     string
+	position int
+}
+
+// Error returns the error message text.
+func (err UnmappedFieldError) Error() string {
+	return fmt.Sprintf(errUnmappedFieldMessage,
+		err.name,
+		err.position)
+}
+
+// NewErrUnmappedField cerate a new error.
+func NewErrUnmappedField(position int, name string) UnmappedFieldError {
+	return UnmappedFieldError{
+		position: position,
+		name:     name,
+	}
+}
@@ -0,0 +1,19 @@
+package errs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrUnmappedField(t *testing.T) {
+	t.Parallel()
+
+	pos := 42
+	name := "createdAt"
+	require.Equal(t,
+		fmt.Sprintf(errUnmappedFieldMessage, name, pos),
+		NewErrUnmappedField(pos, name).Error(),
+	)
+}
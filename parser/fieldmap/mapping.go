@@ -0,0 +1,37 @@
+package fieldmap
+
+import "strings"
+
+// NewMapping creates a new mapping from
+// field names of an API to storage paths.
+func NewMapping(fields map[string]string) *Mapping {
+	mapping := &Mapping{fields: map[string]string{}}
+
+	for name, path := range fields {
+		mapping.fields[name] = path
+	}
+
+	return mapping
+}
+
+// Mapping maps field names of an API to storage paths.
+type Mapping struct {
+	fields map[string]string
+}
+
+// Map returns the storage path of given field name. Names below a mapped
+// name are mapped by their longest mapped prefix, e.g. `author.name`
+// results in `meta.author.name` if `author` is mapped to `meta.author`.
+func (m *Mapping) Map(name string) (string, bool) {
+	if path, exists := m.fields[name]; exists {
+		return path, true
+	}
+
+	for i := strings.LastIndex(name, "."); i > 0; i = strings.LastIndex(name[:i], ".") {
+		if path, exists := m.fields[name[:i]]; exists {
+			return path + name[i:], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,39 @@
+package fieldmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapping(t *testing.T) {
+	t.Parallel()
+
+	mapping := NewMapping(map[string]string{
+		"createdAt":   "meta.created_at",
+		"author":      "meta.author",
+		"author.mail": "contact.email",
+		"id":          "_id",
+	})
+
+	testCases := map[string]string{
+		"createdAt":        "meta.created_at",
+		"id":               "_id",
+		"author":           "meta.author",
+		"author.name":      "meta.author.name",
+		"author.mail":      "contact.email",
+		"author.mail.host": "contact.email.host",
+		"author.0.name":    "meta.author.0.name",
+	}
+
+	for name, expected := range testCases {
+		actual, ok := mapping.Map(name)
+		require.True(t, ok, name)
+		require.Equal(t, expected, actual, name)
+	}
+
+	for _, name := range []string{"unknown", "created", "createdAt2", "authors.name", ".author"} {
+		_, ok := mapping.Map(name)
+		require.False(t, ok, name)
+	}
+}
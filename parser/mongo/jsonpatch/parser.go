@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
 	"github.com/StevenCyb/goapiutils/parser/mongo/jsonpatch/operation"
 	"github.com/StevenCyb/goapiutils/parser/mongo/jsonpatch/validator"
 	"go.mongodb.org/mongo-driver/bson"
@@ -41,9 +42,19 @@ func NewSmartParser(reference reflect.Type) (*Parser, error) {
 // Parser that can parse patch operation to generate mongo queries.
 type Parser struct {
 	validator *validator.Validator
+	mapping   *fieldmap.Mapping
 	policies  []Policy
 }
 
+// SetFieldMapping sets a mapping from the paths used in operations to storage paths.
+// Paths without mapping are rejected, policies are tested with the unmapped paths
+// and the validator of the smart parser with the storage paths.
+func (p *Parser) SetFieldMapping(mapping *fieldmap.Mapping) *Parser {
+	p.mapping = mapping
+
+	return p
+}
+
 // Parse given operation spec to generate mongo queries if not violating policies.
 func (p Parser) Parse(operationSpecs ...operation.Spec) (bson.A, error) {
 	if len(operationSpecs) == 0 {
@@ -62,16 +73,50 @@ func (p Parser) Parse(operationSpecs ...operation.Spec) (bson.A, error) {
 		}
 	}
 
+	mappedSpecs, err := p.mapPaths(operationSpecs)
+	if err != nil {
+		return nil, err
+	}
+
 	if p.validator != nil {
-		for _, operationSpec := range operationSpecs {
+		for i, operationSpec := range mappedSpecs {
 			err := p.validator.Validate(operationSpec)
 			if err != nil {
-				return nil, fmt.Errorf("operation '%+v' invalid: %w", operationSpec, err)
+				return nil, fmt.Errorf("operation '%+v' invalid: %w", operationSpecs[i], err)
+			}
+		}
+	}
+
+	return p.generateMongoQuery(mappedSpecs...)
+}
+
+// mapPaths returns a copy of given operation specs with the paths mapped to storage paths.
+// The position of an unmapped path is the index of the operation spec.
+func (p Parser) mapPaths(operationSpecs []operation.Spec) ([]operation.Spec, error) {
+	if p.mapping == nil {
+		return operationSpecs, nil
+	}
+
+	mappedSpecs := make([]operation.Spec, 0, len(operationSpecs))
+
+	for i, operationSpec := range operationSpecs {
+		for _, path := range []*operation.Path{&operationSpec.Path, &operationSpec.From} {
+			if *path == "" {
+				continue
+			}
+
+			mapped, exists := p.mapping.Map(string(*path))
+			if !exists {
+				return nil, errs.NewErrUnmappedField(i, string(*path))
 			}
+
+			*path = operation.Path(mapped)
 		}
+
+		mappedSpecs = append(mappedSpecs, operationSpec)
 	}
 
-	return p.generateMongoQuery(operationSpecs...)
+	return mappedSpecs, nil
 }
 
 // generateMongoQuery generates the mongo query out of operation spec.
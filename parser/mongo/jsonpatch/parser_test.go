@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
 	"github.com/StevenCyb/goapiutils/parser/mongo/jsonpatch/operation"
 	testutil "github.com/StevenCyb/goapiutils/parser/mongo/test_util"
 	"github.com/stretchr/testify/require"
@@ -202,6 +203,62 @@ func TestSmartParsing(t *testing.T) {
 	})
 }
 
+func TestFieldMapping(t *testing.T) {
+	t.Parallel()
+
+	mapping := fieldmap.NewMapping(map[string]string{
+		"title":  "a",
+		"groups": "meta.groups",
+	})
+
+	t.Run("WithMappedPaths_Success", func(t *testing.T) {
+		t.Parallel()
+
+		ExecuteSuccessTest(t, *NewParser().SetFieldMapping(mapping),
+			bson.A{
+				bson.M{"$set": bson.M{"a": "$meta.groups.1"}},
+				bson.M{"$unset": "meta.groups.1"},
+			},
+			operation.Spec{Operation: operation.MoveOperation, Path: "title", From: "groups.1"},
+		)
+	})
+
+	t.Run("WithUnmappedPath_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		ExecuteFailedTest(t, *NewParser().SetFieldMapping(mapping),
+			errs.NewErrUnmappedField(1, "b"),
+			operation.Spec{Operation: operation.RemoveOperation, Path: "title"},
+			operation.Spec{Operation: operation.RemoveOperation, Path: "b"},
+		)
+	})
+
+	t.Run("WithPolicyOnUnmappedPath_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		ExecuteFailedTest(t,
+			*NewParser(DisallowPathPolicy{Details: "NoTitle", Path: "title"}).SetFieldMapping(mapping),
+			errs.NewErrPolicyViolation("NoTitle"),
+			operation.Spec{Operation: operation.RemoveOperation, Path: "title"},
+		)
+	})
+
+	t.Run("WithSmartParser_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(DummyDoc{}))
+		require.NoError(t, err)
+
+		parser.SetFieldMapping(fieldmap.NewMapping(map[string]string{"id": "_id", "title": "a"}))
+
+		_, err = parser.Parse(operation.Spec{Operation: operation.ReplaceOperation, Path: "title", Value: "new"})
+		require.NoError(t, err)
+
+		_, err = parser.Parse(operation.Spec{Operation: operation.ReplaceOperation, Path: "id", Value: "new"})
+		require.ErrorContains(t, err, "operation '{From: Path:id")
+	})
+}
+
 func TestInterpretation(t *testing.T) {
 	t.Parallel()
 
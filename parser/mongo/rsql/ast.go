@@ -0,0 +1,149 @@
+package rsql
+
+// Operator is a comparison operator of a query.
+type Operator string
+
+// Comparison operators that are supported by this parser.
+const (
+	EqualOperator              Operator = "=="
+	NotEqualOperator           Operator = "!="
+	GreaterThanOperator        Operator = "=gt="
+	GreaterThanOrEqualOperator Operator = "=ge="
+	LessThanOperator           Operator = "=lt="
+	LessThanOrEqualOperator    Operator = "=le="
+	StartsWithOperator         Operator = "=sw="
+	EndsWithOperator           Operator = "=ew="
+	InOperator                 Operator = "=in="
+	NotInOperator              Operator = "=out="
+
+	LikeOperator                      Operator = "=like="
+	ContainsOperator                  Operator = "=contains="
+	CaseInsensitiveStartsWithOperator Operator = "=isw="
+	CaseInsensitiveEndsWithOperator   Operator = "=iew="
+	CaseInsensitiveEqualOperator      Operator = "=ieq="
+
+	ExistsOperator Operator = "=exists="
+	NullOperator   Operator = "=null="
+
+	AllOperator       Operator = "=all="
+	SizeOperator      Operator = "=size="
+	ElemMatchOperator Operator = "=em="
+
+	NearOperator   Operator = "=near="
+	WithinOperator Operator = "=within="
+	BoxOperator    Operator = "=box="
+
+	// TextOperator searches the text index with the argument, it is only used on the pseudo-field `*`.
+	TextOperator Operator = "=text="
+)
+
+// TextSearchField is the pseudo-field of text searches like `*=text="coffee"`.
+const TextSearchField = "*"
+
+// LiteralKind is the kind of a literal.
+type LiteralKind string
+
+// Kinds of literals that are supported by this parser.
+const (
+	OidLiteralKind     LiteralKind = "OID"
+	BoolLiteralKind    LiteralKind = "BOOL"
+	StringLiteralKind  LiteralKind = "STRING"
+	IntLiteralKind     LiteralKind = "INT"
+	FloatLiteralKind   LiteralKind = "FLOAT"
+	ListLiteralKind    LiteralKind = "LIST"
+	NullLiteralKind    LiteralKind = "NULL"
+	DateLiteralKind    LiteralKind = "DATE"
+	DecimalLiteralKind LiteralKind = "DECIMAL"
+	// PlaceholderLiteralKind is a placeholder of a template that is replaced when it is bound.
+	PlaceholderLiteralKind LiteralKind = "PLACEHOLDER"
+)
+
+// Literal is a typed value of a comparison.
+// The value is a `primitive.ObjectID`, `bool`, `string`, `int64` (or `int32` with the smart
+// parser or numeric options), `float64`, `primitive.Decimal128`, `time.Time`, `[]Literal`
+// or `nil` depending on the kind. The value of a placeholder is its name.
+type Literal struct {
+	Value    interface{}
+	Kind     LiteralKind
+	Position int
+}
+
+// Node is a node of the abstract syntax tree.
+type Node interface {
+	// GetPosition returns the position in the query.
+	GetPosition() int
+}
+
+// AndNode combines children with a logical AND.
+type AndNode struct {
+	Children []Node
+	Position int
+}
+
+// GetPosition returns the position in the query.
+func (n *AndNode) GetPosition() int {
+	return n.Position
+}
+
+// OrNode combines children with a logical OR.
+type OrNode struct {
+	Children []Node
+	Position int
+}
+
+// GetPosition returns the position in the query.
+func (n *OrNode) GetPosition() int {
+	return n.Position
+}
+
+// GroupNode is an expression in round brackets.
+type GroupNode struct {
+	Child    Node
+	Position int
+}
+
+// GetPosition returns the position in the query.
+func (n *GroupNode) GetPosition() int {
+	return n.Position
+}
+
+// NotNode negates the child.
+type NotNode struct {
+	Child    Node
+	Position int
+}
+
+// GetPosition returns the position in the query.
+func (n *NotNode) GetPosition() int {
+	return n.Position
+}
+
+// ComparisonNode compares a field with a literal. If the field is mapped,
+// the alias contains the name of the field as used in the query.
+type ComparisonNode struct {
+	Field    string
+	Alias    string
+	Operator Operator
+	Argument Literal
+	Position int
+}
+
+// GetPosition returns the position in the query.
+func (n *ComparisonNode) GetPosition() int {
+	return n.Position
+}
+
+// ElemMatchNode matches arrays with at least one element that matches the child.
+// Field names of the child are relative to the array elements. If the field is
+// mapped, the alias contains the name of the field as used in the query.
+type ElemMatchNode struct {
+	Field    string
+	Alias    string
+	Child    Node
+	Position int
+}
+
+// GetPosition returns the position in the query.
+func (n *ElemMatchNode) GetPosition() int {
+	return n.Position
+}
@@ -0,0 +1,352 @@
+package rsql
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Field starts a condition on the field with given name.
+func Field(name string) FieldBuilder {
+	return FieldBuilder{name: name}
+}
+
+// FieldBuilder creates conditions on a field.
+type FieldBuilder struct {
+	name string
+}
+
+// Eq creates a condition with `==`.
+func (f FieldBuilder) Eq(value interface{}) Condition {
+	return f.compare(EqualOperator, value)
+}
+
+// Ne creates a condition with `!=`.
+func (f FieldBuilder) Ne(value interface{}) Condition {
+	return f.compare(NotEqualOperator, value)
+}
+
+// Gt creates a condition with `=gt=`.
+func (f FieldBuilder) Gt(value interface{}) Condition {
+	return f.compare(GreaterThanOperator, value)
+}
+
+// Ge creates a condition with `=ge=`.
+func (f FieldBuilder) Ge(value interface{}) Condition {
+	return f.compare(GreaterThanOrEqualOperator, value)
+}
+
+// Lt creates a condition with `=lt=`.
+func (f FieldBuilder) Lt(value interface{}) Condition {
+	return f.compare(LessThanOperator, value)
+}
+
+// Le creates a condition with `=le=`.
+func (f FieldBuilder) Le(value interface{}) Condition {
+	return f.compare(LessThanOrEqualOperator, value)
+}
+
+// In creates a condition with `=in=`.
+func (f FieldBuilder) In(values ...interface{}) Condition {
+	return f.compareList(InOperator, values)
+}
+
+// Out creates a condition with `=out=`.
+func (f FieldBuilder) Out(values ...interface{}) Condition {
+	return f.compareList(NotInOperator, values)
+}
+
+// All creates a condition with `=all=`.
+func (f FieldBuilder) All(values ...interface{}) Condition {
+	return f.compareList(AllOperator, values)
+}
+
+// StartsWith creates a condition with `=sw=`.
+func (f FieldBuilder) StartsWith(value string) Condition {
+	return f.compare(StartsWithOperator, value)
+}
+
+// EndsWith creates a condition with `=ew=`.
+func (f FieldBuilder) EndsWith(value string) Condition {
+	return f.compare(EndsWithOperator, value)
+}
+
+// Contains creates a condition with `=contains=`.
+func (f FieldBuilder) Contains(value string) Condition {
+	return f.compare(ContainsOperator, value)
+}
+
+// Like creates a condition with `=like=`, `*` is used as wildcard.
+func (f FieldBuilder) Like(value string) Condition {
+	return f.compare(LikeOperator, value)
+}
+
+// IStartsWith creates a condition with `=isw=`.
+func (f FieldBuilder) IStartsWith(value string) Condition {
+	return f.compare(CaseInsensitiveStartsWithOperator, value)
+}
+
+// IEndsWith creates a condition with `=iew=`.
+func (f FieldBuilder) IEndsWith(value string) Condition {
+	return f.compare(CaseInsensitiveEndsWithOperator, value)
+}
+
+// IEq creates a condition with `=ieq=`.
+func (f FieldBuilder) IEq(value string) Condition {
+	return f.compare(CaseInsensitiveEqualOperator, value)
+}
+
+// Exists creates a condition with `=exists=`.
+func (f FieldBuilder) Exists(exists bool) Condition {
+	return f.compare(ExistsOperator, exists)
+}
+
+// Null creates a condition with `=null=`.
+func (f FieldBuilder) Null(null bool) Condition {
+	return f.compare(NullOperator, null)
+}
+
+// Size creates a condition with `=size=`.
+func (f FieldBuilder) Size(size int) Condition {
+	if size < 0 {
+		return Condition{err: fmt.Errorf("%w: negative size '%d'", ErrUnserializable, size)}
+	}
+
+	return f.compare(SizeOperator, size)
+}
+
+// Near creates a condition with `=near=`, the distance is in meters.
+func (f FieldBuilder) Near(longitude, latitude, distance float64) Condition {
+	return f.compareList(NearOperator, []interface{}{longitude, latitude, distance})
+}
+
+// Within creates a condition with `=within=`, the distance is in meters.
+func (f FieldBuilder) Within(longitude, latitude, distance float64) Condition {
+	return f.compareList(WithinOperator, []interface{}{longitude, latitude, distance})
+}
+
+// Box creates a condition with `=box=` of the bottom left and upper right corner.
+func (f FieldBuilder) Box(minLongitude, minLatitude, maxLongitude, maxLatitude float64) Condition {
+	return f.compareList(BoxOperator, []interface{}{minLongitude, minLatitude, maxLongitude, maxLatitude})
+}
+
+// ElemMatch creates a condition with `=em=`, the fields
+// of the condition are relative to the array elements.
+func (f FieldBuilder) ElemMatch(condition Condition) Condition {
+	if condition.err != nil {
+		return condition
+	}
+
+	if err := f.validate(); err != nil {
+		return Condition{err: err}
+	}
+
+	return Condition{node: &ElemMatchNode{Field: f.name, Child: condition.node}}
+}
+
+// compare creates a comparison with a single literal.
+func (f FieldBuilder) compare(operator Operator, value interface{}) Condition {
+	if err := f.validate(); err != nil {
+		return Condition{err: err}
+	}
+
+	literal, err := builderLiteral(value)
+	if err != nil {
+		return Condition{err: err}
+	}
+
+	return Condition{node: &ComparisonNode{Field: f.name, Operator: operator, Argument: literal}}
+}
+
+// compareList creates a comparison with a list of literals.
+func (f FieldBuilder) compareList(operator Operator, values []interface{}) Condition {
+	if err := f.validate(); err != nil {
+		return Condition{err: err}
+	}
+
+	if len(values) == 0 {
+		return Condition{err: fmt.Errorf("%w: empty list for '%s'", ErrUnserializable, operator)}
+	}
+
+	items := make([]Literal, 0, len(values))
+
+	for _, value := range values {
+		literal, err := builderLiteral(value)
+		if err != nil {
+			return Condition{err: err}
+		}
+
+		items = append(items, literal)
+	}
+
+	return Condition{node: &ComparisonNode{
+		Field: f.name, Operator: operator, Argument: Literal{Kind: ListLiteralKind, Value: items},
+	}}
+}
+
+// validate checks the field name like the parser does.
+func (f FieldBuilder) validate() error {
+	return tokenizer.ValidateFieldName(f.name, 0) //nolint:wrapcheck
+}
+
+// builderLiteral converts a Go value into a literal.
+//
+//nolint:cyclop
+func builderLiteral(value interface{}) (Literal, error) {
+	switch value := value.(type) {
+	case int:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case int8:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case int16:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case int32:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case uint8:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case uint16:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case uint32:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case uint:
+		return builderLiteral(uint64(value))
+	case uint64:
+		if value > math.MaxInt64 {
+			return Literal{}, fmt.Errorf("%w: integer '%d' overflows int64", ErrUnserializable, value)
+		}
+
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, nil
+	case float32:
+		return Literal{Kind: FloatLiteralKind, Value: float64(value)}, nil
+	case time.Time:
+		return Literal{Kind: DateLiteralKind, Value: value}, nil
+	case primitive.DateTime:
+		return Literal{Kind: DateLiteralKind, Value: value.Time()}, nil
+	}
+
+	if literal, ok := decodeLiteral(value); ok && literal.Kind != ListLiteralKind {
+		return literal, nil
+	}
+
+	return Literal{}, fmt.Errorf("%w: value of type '%T'", ErrUnserializable, value)
+}
+
+// TextSearch creates a text search with `*=text=`.
+func TextSearch(search string) Condition {
+	return Condition{node: &ComparisonNode{
+		Field: TextSearchField, Operator: TextOperator, Argument: Literal{Kind: StringLiteralKind, Value: search},
+	}}
+}
+
+// Condition is a part of a query created with `Field`.
+// Errors of invalid fields or values are returned by `Build`.
+type Condition struct {
+	node Node
+	err  error
+}
+
+// And combines the condition with others using `;`.
+func (c Condition) And(others ...Condition) Condition {
+	return combine(append([]Condition{c}, others...), false)
+}
+
+// Or combines the condition with others using `,`.
+func (c Condition) Or(others ...Condition) Condition {
+	return combine(append([]Condition{c}, others...), true)
+}
+
+// Not negates the condition.
+func (c Condition) Not() Condition {
+	if c.err != nil {
+		return c
+	}
+
+	return Condition{node: &NotNode{Child: c.node}}
+}
+
+// And combines given conditions using `;`.
+func And(conditions ...Condition) Condition {
+	return combine(conditions, false)
+}
+
+// Or combines given conditions using `,`.
+func Or(conditions ...Condition) Condition {
+	return combine(conditions, true)
+}
+
+// Not negates given condition.
+func Not(condition Condition) Condition {
+	return condition.Not()
+}
+
+// combine joins conditions into a composite and
+// merges nested composites of the same kind.
+func combine(conditions []Condition, or bool) Condition {
+	children := make([]Node, 0, len(conditions))
+
+	for _, condition := range conditions {
+		switch {
+		case condition.err != nil:
+			return condition
+		case condition.node == nil:
+			return Condition{err: fmt.Errorf("%w: empty condition", ErrUnserializable)}
+		}
+
+		switch node := condition.node.(type) {
+		case *AndNode:
+			if !or {
+				children = append(children, node.Children...)
+
+				continue
+			}
+		case *OrNode:
+			if or {
+				children = append(children, node.Children...)
+
+				continue
+			}
+		}
+
+		children = append(children, condition.node)
+	}
+
+	if or {
+		return Condition{node: joinNodes(children, func(children []Node) Node { return &OrNode{Children: children} })}
+	}
+
+	return Condition{node: joinNodes(children, func(children []Node) Node { return &AndNode{Children: children} })}
+}
+
+// Node returns the abstract syntax tree of the condition.
+func (c Condition) Node() (Node, error) {
+	return c.node, c.err
+}
+
+// Build returns the query of the condition. The query is
+// checked with a parser to guarantee that it is accepted.
+func (c Condition) Build() (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+
+	query, err := Serialize(c.node)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := NewParser(nil).ParseAST(query); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnserializable, err.Error())
+	}
+
+	return query, nil
+}
+
+// String returns the query of the condition or an empty string if it is invalid.
+func (c Condition) String() string {
+	query, _ := c.Build()
+
+	return query
+}
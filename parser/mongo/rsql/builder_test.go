@@ -0,0 +1,112 @@
+package rsql
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+//nolint:funlen
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Build_Success", func(t *testing.T) {
+		t.Parallel()
+
+		oid, _ := primitive.ObjectIDFromHex("5ca9d6e4a8b1f8a6c0e4d1a2")
+		date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		for expected, condition := range map[string]Condition{
+			`age=gt=18;name=sw="A"`:              Field("age").Gt(18).And(Field("name").StartsWith("A")),
+			`a==1;b==2;c==3`:                     And(Field("a").Eq(1), Field("b").Eq(uint8(2)).And(Field("c").Eq(int64(3)))),
+			`a==1,(b==2;c==3)`:                   Field("a").Eq(1).Or(Field("b").Eq(2).And(Field("c").Eq(3))),
+			`!(a==1,b==2)`:                       Not(Or(Field("a").Eq(1), Field("b").Eq(2))),
+			`a=in=(1,"x",null,true)`:             Field("a").In(1, "x", nil, true),
+			`a=out=(1.5)`:                        Field("a").Out(1.5),
+			`tags=all=("a","b")`:                 Field("tags").All("a", "b"),
+			`tags=size=2`:                        Field("tags").Size(2),
+			`a=exists=true;b=null=false`:         Field("a").Exists(true).And(Field("b").Null(false)),
+			`a=ew="x";b=contains="y"`:            Field("a").EndsWith("x").And(Field("b").Contains("y")),
+			`a=like="x*";b=isw="y"`:              Field("a").Like("x*").And(Field("b").IStartsWith("y")),
+			`a=iew="x";b=ieq="y"`:                Field("a").IEndsWith("x").And(Field("b").IEq("y")),
+			`a!=1;b=ge=2;c=lt=3;d=le=4`:          And(Field("a").Ne(1), Field("b").Ge(2), Field("c").Lt(3), Field("d").Le(4)),
+			`items=em=(sku=="x";qty=gt=1)`:       Field("items").ElemMatch(Field("sku").Eq("x").And(Field("qty").Gt(1))),
+			`id==$oid(5ca9d6e4a8b1f8a6c0e4d1a2)`: Field("id").Eq(oid),
+			`at=ge=$date(2024-01-02T03:04:05Z)`:  Field("at").Ge(date),
+			`name=="a,%20b;c$%25\"x\""`:          Field("name").Eq(`a, b;c$%"x"`),
+			`q=="a%26b%23c%2Bd"`:                 Field("q").Eq("a&b#c+d"),
+			`\true==1;a\=b==2`:                   Field("true").Eq(1).And(Field("a=b").Eq(2)),
+			`l=near=(13.4,52.52,1000.0)`:         Field("l").Near(13.4, 52.52, 1000),
+			`l=within=(-1.5,2.0,10.0)`:           Field("l").Within(-1.5, 2, 10),
+			`l=box=(0.0,0.0,1.0,1.0)`:            Field("l").Box(0, 0, 1, 1),
+		} {
+			actual, err := condition.Build()
+			require.NoError(t, err, expected)
+			require.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("Parse_Success", func(t *testing.T) {
+		t.Parallel()
+
+		query, err := Field("name").Eq("x=y, (z)").And(Field("age").Gt(18)).Build()
+		require.NoError(t, err)
+
+		actual, err := NewParser(nil).Parse(query)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "$and", Value: bson.A{
+			bson.D{bson.E{Key: "name", Value: "x=y, (z)"}},
+			bson.D{bson.E{Key: "age", Value: bson.D{bson.E{Key: "$gt", Value: int64(18)}}}},
+		}}}, actual)
+	})
+
+	t.Run("URL_Success", func(t *testing.T) {
+		t.Parallel()
+
+		query, err := Field("q").Eq("a & b #1+1").Build()
+		require.NoError(t, err)
+
+		link, err := url.Parse("https://example.com/items?query=" + query + "&page=2")
+		require.NoError(t, err)
+		require.Equal(t, "2", link.Query().Get("page"))
+
+		actual, err := NewParser(nil).Parse(link.Query().Get("query"))
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "q", Value: "a & b #1+1"}}, actual)
+	})
+
+	t.Run("String_Success", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, `a==1`, Field("a").Eq(1).String())
+		require.Equal(t, "", Field("a").Eq(struct{}{}).String())
+	})
+
+	t.Run("InvalidFieldName_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Field("a").Eq(1).And(Field("$where").Eq(1)).Build()
+		require.Equal(t, errs.NewErrInvalidFieldName(0, "$where", tokenizer.OperatorSegmentReason), err)
+	})
+
+	t.Run("Unserializable_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for _, condition := range []Condition{
+			Field("a").Eq(struct{}{}),
+			Field("a").Eq(uint64(1 << 63)),
+			Field("a").In(),
+			Field("a").Size(-1),
+			Field("a").Eq(1).And(Condition{}),
+		} {
+			_, err := condition.Build()
+			require.ErrorIs(t, err, ErrUnserializable)
+		}
+	})
+}
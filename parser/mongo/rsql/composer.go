@@ -0,0 +1,155 @@
+package rsql
+
+import (
+	"strings"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Composer ANDs enforced conditions like `tenant_id==X` with the filters of user queries,
+// so the result is a single filter that always contains the conditions, even if the user
+// query is empty or has a top-level `$or`. A composer can be used by multiple goroutines.
+type Composer struct {
+	parser   *Parser
+	enforced []Node
+	fields   []string
+}
+
+// NewComposer creates a composer that parses user queries with given parser and ANDs them with
+// the enforced conditions. The fields of the conditions are storage paths, user queries that
+// reference them (or their parents and children) are rejected with an `errs.PolicyViolationError`.
+// A smart parser checks the conditions and coerces their values.
+func NewComposer(parser *Parser, enforced ...Condition) (*Composer, error) {
+	node, err := And(enforced...).Node()
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil {
+		return nil, ErrNoConditions
+	}
+
+	if parser.schema != nil {
+		if err := parser.schema.apply(node, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	composer := &Composer{parser: parser, enforced: []Node{node}}
+	if and, ok := node.(*AndNode); ok {
+		composer.enforced = and.Children
+	}
+
+	collectFields(node, "", &composer.fields)
+
+	return composer, nil
+}
+
+// Parse parses a user query into a MongoDB filter that contains the enforced conditions.
+func (c *Composer) Parse(query string) (bson.D, error) {
+	node, err := c.ParseAST(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parser.emit(node)
+}
+
+// ParseAST parses a user query into an abstract syntax tree that contains the enforced conditions.
+func (c *Composer) ParseAST(query string) (Node, error) {
+	node, err := c.parser.ParseAST(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkFields(node, ""); err != nil {
+		return nil, err
+	}
+
+	children := append([]Node{}, c.enforced...)
+
+	switch node := node.(type) {
+	case nil:
+	case *AndNode:
+		children = append(children, node.Children...)
+	default:
+		children = append(children, node)
+	}
+
+	return joinNodes(children, func(children []Node) Node { return &AndNode{Children: children} }), nil
+}
+
+// checkFields rejects comparisons of a user query on enforced fields.
+func (c *Composer) checkFields(node Node, prefix string) error {
+	switch node := node.(type) {
+	case *AndNode:
+		return c.checkAllFields(node.Children, prefix)
+	case *OrNode:
+		return c.checkAllFields(node.Children, prefix)
+	case *GroupNode:
+		return c.checkFields(node.Child, prefix)
+	case *NotNode:
+		return c.checkFields(node.Child, prefix)
+	case *ElemMatchNode:
+		field := prefix + node.Field
+		if c.isEnforced(field) {
+			return errs.NewErrFieldPolicyViolation(node.Position,
+				displayName(field, node.Alias), string(ElemMatchOperator), "field is enforced")
+		}
+
+		return c.checkFields(node.Child, field+".")
+	case *ComparisonNode:
+		field := prefix + node.Field
+		if c.isEnforced(field) {
+			return errs.NewErrFieldPolicyViolation(node.Position,
+				displayName(field, node.Alias), string(node.Operator), "field is enforced")
+		}
+	}
+
+	return nil
+}
+
+// checkAllFields rejects comparisons of given nodes on enforced fields.
+func (c *Composer) checkAllFields(nodes []Node, prefix string) error {
+	for _, node := range nodes {
+		if err := c.checkFields(node, prefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isEnforced checks if a field, one of its parents or children is enforced.
+func (c *Composer) isEnforced(field string) bool {
+	for _, enforced := range c.fields {
+		if field == enforced || strings.HasPrefix(field, enforced+".") || strings.HasPrefix(enforced, field+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// collectFields collects the storage paths of the fields of a node.
+func collectFields(node Node, prefix string, fields *[]string) {
+	switch node := node.(type) {
+	case *AndNode:
+		for _, child := range node.Children {
+			collectFields(child, prefix, fields)
+		}
+	case *OrNode:
+		for _, child := range node.Children {
+			collectFields(child, prefix, fields)
+		}
+	case *GroupNode:
+		collectFields(node.Child, prefix, fields)
+	case *NotNode:
+		collectFields(node.Child, prefix, fields)
+	case *ElemMatchNode:
+		*fields = append(*fields, prefix+node.Field)
+	case *ComparisonNode:
+		*fields = append(*fields, prefix+node.Field)
+	}
+}
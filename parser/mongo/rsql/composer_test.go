@@ -0,0 +1,134 @@
+package rsql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+//nolint:funlen
+func TestComposer(t *testing.T) {
+	t.Parallel()
+
+	newComposer := func(t *testing.T, parser *Parser) *Composer {
+		t.Helper()
+
+		composer, err := NewComposer(parser, Field("tenant_id").Eq("t1"), Field("deleted").Eq(false))
+		require.NoError(t, err)
+
+		return composer
+	}
+
+	enforced := bson.A{
+		bson.D{bson.E{Key: "tenant_id", Value: "t1"}},
+		bson.D{bson.E{Key: "deleted", Value: false}},
+	}
+
+	t.Run("Parse_Success", func(t *testing.T) {
+		t.Parallel()
+
+		composer := newComposer(t, NewParser(nil))
+
+		for query, expected := range map[string]bson.D{
+			``: {bson.E{Key: "$and", Value: enforced}},
+			`name=="x"`: {bson.E{Key: "$and", Value: append(append(bson.A{}, enforced...),
+				bson.D{bson.E{Key: "name", Value: "x"}},
+			)}},
+			`name=="x";age==1`: {bson.E{Key: "$and", Value: append(append(bson.A{}, enforced...),
+				bson.D{bson.E{Key: "name", Value: "x"}},
+				bson.D{bson.E{Key: "age", Value: int64(1)}},
+			)}},
+			`name=="x",age==1`: {bson.E{Key: "$and", Value: append(append(bson.A{}, enforced...),
+				bson.D{bson.E{Key: "$or", Value: bson.A{
+					bson.D{bson.E{Key: "name", Value: "x"}},
+					bson.D{bson.E{Key: "age", Value: int64(1)}},
+				}}},
+			)}},
+		} {
+			actual, err := composer.Parse(query)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, actual, query)
+		}
+	})
+
+	t.Run("SingleCondition_Success", func(t *testing.T) {
+		t.Parallel()
+
+		composer, err := NewComposer(NewParser(nil), Field("tenant_id").Eq("t1"))
+		require.NoError(t, err)
+
+		actual, err := composer.Parse(``)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "tenant_id", Value: "t1"}}, actual)
+	})
+
+	t.Run("WithSmartParser_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+		require.NoError(t, err)
+
+		composer, err := NewComposer(parser, Field("level").Eq(1))
+		require.NoError(t, err)
+
+		actual, err := composer.Parse(`name=="x"`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "$and", Value: bson.A{
+			bson.D{bson.E{Key: "level", Value: int32(1)}},
+			bson.D{bson.E{Key: "name", Value: "x"}},
+		}}}, actual)
+	})
+
+	t.Run("EnforcedFields_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		composer := newComposer(t, NewParser(nil))
+
+		for query, expected := range map[string]error{
+			`tenant_id=="t2"`: errs.NewErrFieldPolicyViolation(0, "tenant_id", "==", "field is enforced"),
+			`name=="x",!(deleted==true)`: errs.NewErrFieldPolicyViolation(12, "deleted", "==",
+				"field is enforced"),
+			`tenant_id.name=="x"`: errs.NewErrFieldPolicyViolation(0, "tenant_id.name", "==", "field is enforced"),
+			`tenant_id=em=(a==1)`: errs.NewErrFieldPolicyViolation(0, "tenant_id", "=em=", "field is enforced"),
+		} {
+			_, err := composer.Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+
+	t.Run("EnforcedNestedFields_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		composer, err := NewComposer(NewParser(nil), Field("meta.tenant").Eq("t1"))
+		require.NoError(t, err)
+
+		for query, expected := range map[string]error{
+			`meta=exists=true`:       errs.NewErrFieldPolicyViolation(0, "meta", "=exists=", "field is enforced"),
+			`meta=em=(tenant=="t2")`: errs.NewErrFieldPolicyViolation(0, "meta", "=em=", "field is enforced"),
+		} {
+			_, err := composer.Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+
+	t.Run("WithFieldMapping_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		// enforced fields are storage paths, errors use the names of the query
+		parser := NewParser(nil).SetFieldMapping(fieldmap.NewMapping(map[string]string{"tenant": "tenant_id"}))
+
+		_, err := newComposer(t, parser).Parse(`tenant=="t2"`)
+		require.Equal(t, errs.NewErrFieldPolicyViolation(0, "tenant", "==", "field is enforced"), err)
+	})
+
+	t.Run("NoConditions_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewComposer(NewParser(nil))
+		require.ErrorIs(t, err, ErrNoConditions)
+	})
+}
@@ -0,0 +1,80 @@
+package rsql
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// relativeDateFormat is the format of a relative date offset e.g. `-7d` or `+1d12h`.
+// The empty offset of `$now()` is handled separately.
+//
+//nolint:gochecknoglobals
+var relativeDateFormat = regexp.MustCompile(`^([+-]?)((?:\d+[smhdw])+)$`)
+
+// relativeDatePart is a single part of a relative date offset.
+//
+//nolint:gochecknoglobals
+var relativeDatePart = regexp.MustCompile(`(\d+)([smhdw])`)
+
+// relativeDateUnits maps the units of a relative date offset to durations.
+//
+//nolint:gochecknoglobals
+var relativeDateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": day,
+	"w": 7 * day,
+}
+
+// parseDate parses an RFC3339 timestamp or an ISO-8601 date.
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+
+	date, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	return date, nil
+}
+
+// parseRelativeDate resolves an offset like `-7d` against given time.
+func parseRelativeDate(offset string, now time.Time) (time.Time, error) {
+	if offset == "" {
+		return now, nil
+	}
+
+	match := relativeDateFormat.FindStringSubmatch(offset)
+	if match == nil {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	var duration time.Duration
+
+	for _, part := range relativeDatePart.FindAllStringSubmatch(match[2], -1) {
+		amount, err := strconv.ParseInt(part[1], intBase, int64Size)
+		if err != nil {
+			return time.Time{}, ErrInvalidDate
+		}
+
+		unit := relativeDateUnits[part[2]]
+		if amount > math.MaxInt64/int64(unit) || time.Duration(amount)*unit > math.MaxInt64-duration {
+			return time.Time{}, ErrInvalidDate
+		}
+
+		duration += time.Duration(amount) * unit
+	}
+
+	if match[1] == "-" {
+		duration = -duration
+	}
+
+	return now.Add(duration), nil
+}
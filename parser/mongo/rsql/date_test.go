@@ -0,0 +1,48 @@
+package rsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	date, err := parseDate("2024-01-02")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), date)
+
+	date, err = parseDate("2024-01-02T03:04:05+01:00")
+	require.NoError(t, err)
+	require.True(t, time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC).Equal(date))
+
+	_, err = parseDate("yesterday")
+	require.ErrorIs(t, err, ErrInvalidDate)
+}
+
+func TestParseRelativeDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	testCases := map[string]time.Time{
+		"":      now,
+		"-7d":   time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC),
+		"+1w":   time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC),
+		"1d12h": time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+		"-30m":  time.Date(2024, 1, 10, 11, 30, 0, 0, time.UTC),
+		"-10s":  time.Date(2024, 1, 10, 11, 59, 50, 0, time.UTC),
+	}
+
+	for offset, expected := range testCases {
+		actual, err := parseRelativeDate(offset, now)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, offset)
+	}
+
+	for _, offset := range []string{"-7x", "+", "-", "9223372036854775807w", "106751d106751d"} {
+		_, err := parseRelativeDate(offset, now)
+		require.ErrorIs(t, err, ErrInvalidDate, offset)
+	}
+}
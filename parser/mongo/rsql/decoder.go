@@ -0,0 +1,328 @@
+package rsql
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Decode converts a MongoDB filter created by the parser back into an abstract syntax tree.
+// Filters that can't be expressed as query result in an `UnsupportedFilterError`.
+// An empty filter results in a nil node.
+func Decode(filter bson.D) (Node, error) {
+	nodes := make([]Node, 0, len(filter))
+
+	for _, element := range filter {
+		node, err := decodeElement(element)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return joinNodes(nodes, func(children []Node) Node { return &AndNode{Children: children} }), nil
+}
+
+// joinNodes combines nodes with given composite if there is more than one.
+func joinNodes(nodes []Node, composite func(children []Node) Node) Node {
+	switch len(nodes) {
+	case 0:
+		return nil
+	case 1:
+		return nodes[0]
+	}
+
+	return composite(nodes)
+}
+
+// decodeElement converts a single filter element into a node.
+func decodeElement(element bson.E) (Node, error) {
+	switch element.Key {
+	case "$and", "$or", "$nor":
+		nodes, err := decodeList(element)
+		if err != nil {
+			return nil, err
+		}
+
+		switch element.Key {
+		case "$and":
+			return &AndNode{Children: nodes}, nil
+		case "$or":
+			return &OrNode{Children: nodes}, nil
+		}
+
+		return &NotNode{Child: joinNodes(nodes, func(children []Node) Node { return &OrNode{Children: children} })}, nil
+	}
+
+	if element.Key == "$text" {
+		return decodeTextSearch(element)
+	}
+
+	if element.Key == "" || strings.HasPrefix(element.Key, "$") {
+		return nil, UnsupportedFilterError{key: element.Key}
+	}
+
+	return decodeField(element.Key, element.Value)
+}
+
+// decodeList converts the filters of a logical operation into nodes.
+func decodeList(element bson.E) ([]Node, error) {
+	items, ok := element.Value.(bson.A)
+	if !ok || len(items) == 0 {
+		return nil, UnsupportedFilterError{key: element.Key}
+	}
+
+	nodes := make([]Node, 0, len(items))
+
+	for _, item := range items {
+		filter, ok := item.(bson.D)
+		if !ok || len(filter) == 0 {
+			return nil, UnsupportedFilterError{key: element.Key}
+		}
+
+		node, err := Decode(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+// decodeField converts the condition of a field into a node.
+func decodeField(field string, value interface{}) (Node, error) {
+	switch value := value.(type) {
+	case primitive.Regex:
+		return decodeRegex(field, value)
+	case bson.E:
+		// filters of earlier versions emit `=in=` and `=out=` as a single element instead of a document
+		return decodeOperator(field, value)
+	case bson.D:
+		if len(value) == 0 || !strings.HasPrefix(value[0].Key, "$") {
+			return nil, UnsupportedFilterError{key: field}
+		}
+
+		nodes := make([]Node, 0, len(value))
+
+		for _, element := range value {
+			node, err := decodeOperator(field, element)
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, node)
+		}
+
+		return joinNodes(nodes, func(children []Node) Node { return &AndNode{Children: children} }), nil
+	}
+
+	return decodeComparison(field, EqualOperator, value)
+}
+
+// decodeOperator converts a query operator of a field into a node.
+//
+//nolint:cyclop
+func decodeOperator(field string, element bson.E) (Node, error) {
+	switch element.Key {
+	case "$ne":
+		return decodeComparison(field, NotEqualOperator, element.Value)
+	case "$gt":
+		return decodeComparison(field, GreaterThanOperator, element.Value)
+	case "$gte":
+		return decodeComparison(field, GreaterThanOrEqualOperator, element.Value)
+	case "$lt":
+		return decodeComparison(field, LessThanOperator, element.Value)
+	case "$lte":
+		return decodeComparison(field, LessThanOrEqualOperator, element.Value)
+	case "$in":
+		return decodeListComparison(field, InOperator, element)
+	case "$nin":
+		return decodeListComparison(field, NotInOperator, element)
+	case "$all":
+		return decodeListComparison(field, AllOperator, element)
+	case "$size":
+		return decodeComparison(field, SizeOperator, element.Value)
+	case "$exists":
+		if _, ok := element.Value.(bool); ok {
+			return decodeComparison(field, ExistsOperator, element.Value)
+		}
+	case "$type":
+		if element.Value == "null" {
+			return decodeComparison(field, NullOperator, true)
+		}
+	case "$not":
+		if isNullType(element.Value) {
+			return decodeComparison(field, NullOperator, false)
+		}
+
+		child, err := decodeField(field, element.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return &NotNode{Child: child}, nil
+	case "$nearSphere", "$geoWithin":
+		return decodeGeo(field, element)
+	case "$elemMatch":
+		filter, ok := element.Value.(bson.D)
+		if !ok || len(filter) == 0 {
+			break
+		}
+
+		child, err := Decode(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ElemMatchNode{Field: field, Child: child}, nil
+	}
+
+	return nil, UnsupportedFilterError{key: element.Key}
+}
+
+// isNullType checks if a value is the condition of `=null=true`.
+func isNullType(value interface{}) bool {
+	filter, ok := value.(bson.D)
+
+	return ok && len(filter) == 1 && filter[0].Key == "$type" && filter[0].Value == "null"
+}
+
+// decodeListComparison converts an operator that takes a list into a comparison.
+func decodeListComparison(field string, operator Operator, element bson.E) (Node, error) {
+	if _, ok := element.Value.(bson.A); !ok {
+		return nil, UnsupportedFilterError{key: element.Key}
+	}
+
+	return decodeComparison(field, operator, element.Value)
+}
+
+// decodeComparison creates a comparison of a field with given value.
+func decodeComparison(field string, operator Operator, value interface{}) (Node, error) {
+	literal, ok := decodeLiteral(value)
+	list := operator == InOperator || operator == NotInOperator || operator == AllOperator
+
+	if !ok || list != (literal.Kind == ListLiteralKind) {
+		return nil, UnsupportedFilterError{key: field}
+	}
+
+	return &ComparisonNode{Field: field, Operator: operator, Argument: literal}, nil
+}
+
+// decodeLiteral converts a filter value into a literal.
+//
+//nolint:cyclop
+func decodeLiteral(value interface{}) (Literal, bool) {
+	switch value := value.(type) {
+	case nil:
+		return Literal{Kind: NullLiteralKind}, true
+	case bool:
+		return Literal{Kind: BoolLiteralKind, Value: value}, true
+	case string:
+		return Literal{Kind: StringLiteralKind, Value: value}, true
+	case int:
+		return Literal{Kind: IntLiteralKind, Value: int64(value)}, true
+	case int32:
+		return Literal{Kind: IntLiteralKind, Value: value}, true
+	case int64:
+		return Literal{Kind: IntLiteralKind, Value: value}, true
+	case float64:
+		return Literal{Kind: FloatLiteralKind, Value: value}, true
+	case primitive.Decimal128:
+		return Literal{Kind: DecimalLiteralKind, Value: value}, true
+	case primitive.ObjectID:
+		return Literal{Kind: OidLiteralKind, Value: value}, true
+	case primitive.DateTime:
+		return Literal{Kind: DateLiteralKind, Value: value.Time().UTC()}, true
+	case time.Time:
+		return Literal{Kind: DateLiteralKind, Value: value}, true
+	case bson.A:
+		items := make([]Literal, 0, len(value))
+
+		for _, item := range value {
+			literal, ok := decodeLiteral(item)
+			if !ok || literal.Kind == ListLiteralKind {
+				return Literal{}, false
+			}
+
+			items = append(items, literal)
+		}
+
+		return Literal{Kind: ListLiteralKind, Value: items}, true
+	}
+
+	return Literal{}, false
+}
+
+// decodeRegex converts a regular expression created by `Regex` into a comparison.
+func decodeRegex(field string, regex primitive.Regex) (Node, error) {
+	pattern := regex.Pattern
+	start := strings.HasPrefix(pattern, "^")
+	pattern = strings.TrimPrefix(pattern, "^")
+
+	literal, end, wildcards, ok := unquoteMeta(pattern)
+	if !ok {
+		return nil, UnsupportedFilterError{key: field}
+	}
+
+	var operator Operator
+
+	switch {
+	case regex.Options == "" && start && end:
+		operator = LikeOperator
+	case regex.Options == "" && start:
+		operator = StartsWithOperator
+	case regex.Options == "" && end:
+		operator = EndsWithOperator
+	case regex.Options == "":
+		operator = ContainsOperator
+	case regex.Options == "i" && start && end:
+		operator = CaseInsensitiveEqualOperator
+	case regex.Options == "i" && start:
+		operator = CaseInsensitiveStartsWithOperator
+	case regex.Options == "i" && end:
+		operator = CaseInsensitiveEndsWithOperator
+	}
+
+	// a literal `*` can only be distinguished from a wildcard outside of `=like=`
+	if operator == "" || (operator == LikeOperator && wildcards != strings.Count(literal, "*")) ||
+		(operator != LikeOperator && wildcards > 0) {
+		return nil, UnsupportedFilterError{key: field}
+	}
+
+	return decodeComparison(field, operator, literal)
+}
+
+// unquoteMeta reverses `regexp.QuoteMeta`, reports if the pattern ends with an
+// anchor and counts the wildcards (`.*`) that are converted into `*`.
+func unquoteMeta(pattern string) (string, bool, int, bool) {
+	var (
+		builder   strings.Builder
+		wildcards int
+	)
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			builder.WriteByte(pattern[i])
+		case pattern[i] == '$' && i == len(pattern)-1:
+			return builder.String(), true, wildcards, true
+		case strings.HasPrefix(pattern[i:], ".*"):
+			i++
+			wildcards++
+
+			builder.WriteByte('*')
+		case strings.ContainsRune(`\.+*?()|[]{}^$`, rune(pattern[i])):
+			return "", false, 0, false
+		default:
+			builder.WriteByte(pattern[i])
+		}
+	}
+
+	return builder.String(), false, wildcards, true
+}
@@ -0,0 +1,190 @@
+package rsql
+
+import (
+	"regexp"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+)
+
+// Dialect is the syntax a parser accepts.
+type Dialect byte
+
+const (
+	// NativeDialect is the syntax of this package with
+	// quoted strings and without operator precedence.
+	NativeDialect Dialect = iota
+	// CompatDialect is compatible with the rsql-parser/FIQL grammar. It accepts
+	// unquoted arguments, the keyword composites `and`/`or`, the aliases
+	// `=ne=`, `=nin=`, `<`, `<=`, `>` and `>=` and `;` binds stronger than `,`.
+	CompatDialect
+)
+
+// UnquotedLiteralType is the type of unquoted field names and arguments of the compatibility dialect.
+const UnquotedLiteralType tokenizer.Type = "UNQUOTED_LITERAL"
+
+// operatorAliases maps the operator aliases of the compatibility dialect to the native operators.
+//
+//nolint:gochecknoglobals
+var operatorAliases = map[string]Operator{
+	"=ne=":  NotEqualOperator,
+	"=nin=": NotInOperator,
+	"<":     LessThanOperator,
+	"<=":    LessThanOrEqualOperator,
+	">":     GreaterThanOperator,
+	">=":    GreaterThanOrEqualOperator,
+}
+
+// Expressions to classify unquoted arguments of the compatibility dialect.
+//
+//nolint:gochecknoglobals
+var (
+	unquotedBool = regexp.MustCompile(`(?i)^(true|false)$`)
+	unquotedNull = regexp.MustCompile(`(?i)^null$`)
+)
+
+// compatSpecs returns the tokenizer specs of the compatibility dialect
+// that precede the native specs.
+func compatSpecs() []*tokenizer.Spec {
+	return []*tokenizer.Spec{
+		tokenizer.NewSpec(`(?i)^\s+and\s+`, AndCompositeType),
+		tokenizer.NewSpec(`(?i)^\s+or\s+`, OrCompositeType),
+		tokenizer.NewSpec(`^=ne=`, ValueCompareOperatorType),
+		tokenizer.NewSpec(`^(<=|>=|<|>)`, NumericValueCompareOperatorType),
+		tokenizer.NewSpec(`^=nin=`, ArrayCompareOperatorType),
+	}
+}
+
+// unquotedSpec returns the tokenizer spec for unquoted field names and arguments
+// of the compatibility dialect (characters that are not reserved by the grammar).
+func unquotedSpec() *tokenizer.Spec {
+	return tokenizer.NewSpec(`^(?:[^"'();,=!~<>\s\\]|\\.)+`, UnquotedLiteralType)
+}
+
+// classifyUnquoted converts an unquoted token of the compatibility dialect into
+// a bool, null or number literal like in the native syntax. Other tokens are unescaped.
+func classifyUnquoted(token *tokenizer.Token, unquotedNumber *regexp.Regexp) {
+	switch {
+	case unquotedBool.MatchString(token.Value):
+		token.Type = BoolLiteralType
+	case unquotedNull.MatchString(token.Value):
+		token.Type = NullLiteralType
+	case unquotedNumber.MatchString(token.Value):
+		token.Type = NumberLiteralType
+	default:
+		token.Value = tokenizer.Unescape(token.Value)
+	}
+}
+
+/*
+ * <or_expression>
+ *   : <and_expression>
+ *   | <and_expression> <or_operator> <or_expression>
+ * .
+ */
+func (p *state) orExpression() (Node, error) {
+	children := []Node{}
+
+	for {
+		child, err := p.andExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		children = append(children, child)
+
+		if p.lookahead == nil || p.lookahead.Type != OrCompositeType {
+			break
+		}
+
+		if _, err := p.eat(OrCompositeType); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.lookahead != nil && p.lookahead.Type != ContextEndType {
+		_, err := p.compositeOperation()
+
+		return nil, err
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+
+	return &OrNode{Children: children, Position: children[0].GetPosition()}, nil
+}
+
+/*
+ * <and_expression>
+ *   : <term>
+ *   | <term> <and_operator> <and_expression>
+ * .
+ */
+func (p *state) andExpression() (Node, error) {
+	children := []Node{}
+
+	for {
+		child, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		children = append(children, child)
+
+		if p.lookahead == nil || p.lookahead.Type != AndCompositeType {
+			break
+		}
+
+		if _, err := p.eat(AndCompositeType); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+
+	return &AndNode{Children: children, Position: children[0].GetPosition()}, nil
+}
+
+// fieldName eats the field name of a comparison. Unquoted literals of the
+// compatibility dialect are field names by position and checked against the policy here.
+func (p *state) fieldName() (*tokenizer.Token, error) {
+	if p.dialect == CompatDialect && p.lookahead != nil && isUnquoted(p.lookahead) {
+		if p.policy != nil && !p.policy.Allow(p.prefix+p.lookahead.Value) {
+			return nil, errs.NewErrPolicyViolation(p.prefix + p.lookahead.Value)
+		}
+
+		p.lookahead.Type = FieldNameType
+	}
+
+	return p.eat(FieldNameType)
+}
+
+// unquotedStringLiteral eats an unquoted literal of the compatibility
+// dialect as string, e.g. `=sw=123` starts with the string "123".
+func (p *state) unquotedStringLiteral() (*Literal, error) {
+	if p.lookahead == nil || !isUnquoted(p.lookahead) {
+		_, err := p.eat(QuotedStringLiteralType)
+
+		return nil, err
+	}
+
+	token, err := p.eat(p.lookahead.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Literal{Value: token.Value, Kind: StringLiteralKind, Position: token.Position}, nil
+}
+
+// isUnquoted checks if a token is an unquoted literal of the compatibility dialect.
+func isUnquoted(token *tokenizer.Token) bool {
+	switch token.Type { //nolint:exhaustive
+	case UnquotedLiteralType, BoolLiteralType, NullLiteralType, NumberLiteralType:
+		return true
+	}
+
+	return false
+}
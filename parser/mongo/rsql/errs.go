@@ -0,0 +1,142 @@
+package rsql
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidOperator indicates that a custom operator does not have the format `=name=`.
+	ErrInvalidOperator = errors.New("operator must have the format '=name='")
+	// ErrDuplicateOperator indicates that an operator is already registered or built in.
+	ErrDuplicateOperator = errors.New("operator already registered")
+	// ErrNilHandler indicates that a custom operator was registered without a handler.
+	ErrNilHandler = errors.New("handler is nil")
+	// ErrInvalidDate indicates that a value is neither an RFC3339 timestamp nor an ISO-8601 date.
+	ErrInvalidDate = errors.New("invalid date")
+	// ErrNilReference indicates that a smart parser was created without a reference.
+	ErrNilReference = errors.New("reference is nil")
+	// ErrInvalidReference indicates that the reference of a smart parser is not a struct.
+	ErrInvalidReference = errors.New("reference must be a struct")
+	// ErrUnserializable indicates that a node, filter or condition can not be turned into a query.
+	ErrUnserializable = errors.New("can not be serialized")
+	// ErrNoConditions indicates that a composer was created without enforced conditions.
+	ErrNoConditions = errors.New("no conditions to enforce")
+)
+
+// UnknownFieldError indicate that a field is not part of the reference.
+type UnknownFieldError struct {
+	field    string
+	position int
+}
+
+func (u UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field '%s' at position '%d'", u.field, u.position)
+}
+
+// OperatorMismatchError indicate that an operator can not be used on a field.
+type OperatorMismatchError struct {
+	field     string
+	operator  Operator
+	fieldType string
+	position  int
+}
+
+func (o OperatorMismatchError) Error() string {
+	return fmt.Sprintf("operator '%s' can not be used on field '%s' of type '%s' at position '%d'",
+		o.operator, o.field, o.fieldType, o.position)
+}
+
+// LiteralMismatchError indicate that a literal does not match the type of a field.
+type LiteralMismatchError struct {
+	field     string
+	kind      LiteralKind
+	fieldType string
+	position  int
+}
+
+func (l LiteralMismatchError) Error() string {
+	return fmt.Sprintf("literal of kind '%s' does not match field '%s' of type '%s' at position '%d'",
+		l.kind, l.field, l.fieldType, l.position)
+}
+
+// UnsupportedFilterError indicate that a filter element can not be converted into a query.
+type UnsupportedFilterError struct {
+	key string
+}
+
+func (u UnsupportedFilterError) Error() string {
+	return fmt.Sprintf("filter element '%s' can not be converted into a query", u.key)
+}
+
+// ContradictionError indicate that the conditions on a field can never match together.
+type ContradictionError struct {
+	field string
+}
+
+func (c ContradictionError) Error() string {
+	return fmt.Sprintf("conditions on field '%s' contradict each other", c.field)
+}
+
+// TextSearchError indicate that a text search is used where MongoDB rejects it.
+type TextSearchError struct {
+	reason   string
+	position int
+}
+
+func (t TextSearchError) Error() string {
+	return fmt.Sprintf("text search at position '%d' %s", t.position, t.reason)
+}
+
+// GeoArgumentError indicate that the arguments of a geospatial operator are invalid.
+type GeoArgumentError struct {
+	operator Operator
+	reason   string
+	position int
+}
+
+func (g GeoArgumentError) Error() string {
+	return fmt.Sprintf("invalid arguments of operator '%s' at position '%d': %s", g.operator, g.position, g.reason)
+}
+
+// OperatorPlacementError indicate that an operator is used where MongoDB rejects it.
+type OperatorPlacementError struct {
+	operator Operator
+	reason   string
+	position int
+}
+
+func (o OperatorPlacementError) Error() string {
+	return fmt.Sprintf("operator '%s' at position '%d' %s", o.operator, o.position, o.reason)
+}
+
+// MissingParameterError indicate that no value is bound to a placeholder of a template.
+type MissingParameterError struct {
+	name string
+}
+
+func (m MissingParameterError) Error() string {
+	return fmt.Sprintf("missing value for parameter '%s'", m.name)
+}
+
+// UnknownParameterError indicate that a value is bound to a parameter that is not part of a template.
+type UnknownParameterError struct {
+	name string
+}
+
+func (u UnknownParameterError) Error() string {
+	return fmt.Sprintf("unknown parameter '%s'", u.name)
+}
+
+// ParameterTypeError indicate that a bound value does not match the usage of its placeholder.
+type ParameterTypeError struct {
+	name      string
+	operator  Operator
+	valueType string
+	position  int
+}
+
+func (p ParameterTypeError) Error() string {
+	return fmt.Sprintf("value of type '%s' can not be bound to parameter '%s' of operator '%s' at position '%d'",
+		p.valueType, p.name, p.operator, p.position)
+}
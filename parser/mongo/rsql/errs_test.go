@@ -0,0 +1,82 @@
+package rsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownFieldError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "unknown field 'a.b' at position '3'",
+		UnknownFieldError{field: "a.b", position: 3}.Error())
+}
+
+func TestOperatorMismatchError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "operator '=gt=' can not be used on field 'active' of type 'bool' at position '0'",
+		OperatorMismatchError{field: "active", operator: GreaterThanOperator, fieldType: "bool"}.Error())
+}
+
+func TestLiteralMismatchError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "literal of kind 'STRING' does not match field 'age' of type 'int' at position '5'",
+		LiteralMismatchError{field: "age", kind: StringLiteralKind, fieldType: "int", position: 5}.Error())
+}
+
+func TestUnsupportedFilterError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "filter element '$where' can not be converted into a query",
+		UnsupportedFilterError{key: "$where"}.Error())
+}
+
+func TestContradictionError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "conditions on field 'age' contradict each other",
+		ContradictionError{field: "age"}.Error())
+}
+
+func TestTextSearchError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "text search at position '4' can only be used once",
+		TextSearchError{reason: "can only be used once", position: 4}.Error())
+}
+
+func TestGeoArgumentError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "invalid arguments of operator '=near=' at position '12': latitude '91' is out of range [-90, 90]",
+		GeoArgumentError{operator: NearOperator, reason: "latitude '91' is out of range [-90, 90]", position: 12}.Error())
+}
+
+func TestOperatorPlacementError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "operator '=near=' at position '5' can only be used once",
+		OperatorPlacementError{operator: NearOperator, reason: "can only be used once", position: 5}.Error())
+}
+
+func TestMissingParameterError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "missing value for parameter 'userId'", MissingParameterError{name: "userId"}.Error())
+}
+
+func TestUnknownParameterError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "unknown parameter 'userId'", UnknownParameterError{name: "userId"}.Error())
+}
+
+func TestParameterTypeError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "value of type 'string' can not be bound to parameter 'since' of operator '=gt=' at position '4'",
+		ParameterTypeError{name: "since", operator: GreaterThanOperator, valueType: "string", position: 4}.Error())
+}
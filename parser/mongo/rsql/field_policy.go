@@ -0,0 +1,179 @@
+package rsql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+)
+
+// FieldPolicy restricts how a field can be used in queries.
+// Empty restrictions allow everything.
+type FieldPolicy struct {
+	// Deny rejects any use of the field.
+	Deny bool
+	// Operators are the operators the field can be used with.
+	Operators []Operator
+	// Kinds are the kinds of literals the field can be compared with,
+	// the items of lists are checked individually.
+	Kinds []LiteralKind
+	// Values are the values the field can be compared with e.g. `"active"` or `1`,
+	// the items of lists are checked individually.
+	Values []interface{}
+}
+
+// FieldPolicies maps the field names of queries to their policies. Fields within `=em=`
+// use the full path e.g. `items.sku`. Kinds and values are not checked for the arguments
+// of `=exists=`, `=null=` and `=size=` since they are not compared with the field.
+type FieldPolicies map[string]FieldPolicy
+
+// check returns a `PolicyViolationError` if a node violates a policy.
+func (f FieldPolicies) check(node Node, prefix string) error {
+	switch node := node.(type) {
+	case *AndNode:
+		return f.checkAll(node.Children, prefix)
+	case *OrNode:
+		return f.checkAll(node.Children, prefix)
+	case *GroupNode:
+		return f.check(node.Child, prefix)
+	case *NotNode:
+		return f.check(node.Child, prefix)
+	case *ElemMatchNode:
+		name := displayName(prefix+node.Field, node.Alias)
+
+		if err := f.checkOperator(name, ElemMatchOperator, node.Position); err != nil {
+			return err
+		}
+
+		return f.check(node.Child, name+".")
+	case *ComparisonNode:
+		name := displayName(prefix+node.Field, node.Alias)
+
+		if err := f.checkOperator(name, node.Operator, node.Position); err != nil {
+			return err
+		}
+
+		switch node.Operator { //nolint:exhaustive
+		case ExistsOperator, NullOperator, SizeOperator:
+			return nil
+		}
+
+		return f.checkLiteral(name, node.Operator, node.Argument, node.Position)
+	}
+
+	return nil
+}
+
+// checkAll checks all given nodes.
+func (f FieldPolicies) checkAll(nodes []Node, prefix string) error {
+	for _, node := range nodes {
+		if err := f.check(node, prefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkOperator checks if a field can be used with given operator.
+// Denied fields also deny their child paths e.g. `owner` denies `owner.id`.
+func (f FieldPolicies) checkOperator(name string, operator Operator, position int) error {
+	policy, exists := f[name]
+
+	switch {
+	case f.isDeniedParent(name):
+		return errs.NewErrFieldPolicyViolation(position, name, string(operator), "field is not allowed")
+	case !exists:
+		return nil
+	case policy.Deny:
+		return errs.NewErrFieldPolicyViolation(position, name, string(operator), "field is not allowed")
+	case len(policy.Operators) == 0:
+		return nil
+	}
+
+	for _, allowed := range policy.Operators {
+		if allowed == operator {
+			return nil
+		}
+	}
+
+	return errs.NewErrFieldPolicyViolation(position, name, string(operator), "operator is not allowed")
+}
+
+// isDeniedParent checks if a parent path of a field is denied.
+func (f FieldPolicies) isDeniedParent(name string) bool {
+	for index := strings.LastIndex(name, "."); index > 0; index = strings.LastIndex(name[:index], ".") {
+		if policy, exists := f[name[:index]]; exists && policy.Deny {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkLiteral checks if a field can be compared with the kind and value of a literal.
+func (f FieldPolicies) checkLiteral(name string, operator Operator, literal Literal, position int) error {
+	policy, exists := f[name]
+	if !exists {
+		return nil
+	}
+
+	if items, ok := literal.Value.([]Literal); ok && literal.Kind == ListLiteralKind {
+		for _, item := range items {
+			if err := f.checkLiteral(name, operator, item, position); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if !policy.allowsKind(literal.Kind) {
+		return errs.NewErrFieldPolicyViolation(position, name, string(operator),
+			fmt.Sprintf("literal of kind '%s' is not allowed", literal.Kind))
+	}
+
+	if !policy.allowsValue(literal.Value) {
+		return errs.NewErrFieldPolicyViolation(position, name, string(operator),
+			fmt.Sprintf("value '%v' is not allowed", literal.Value))
+	}
+
+	return nil
+}
+
+// allowsKind checks if the policy allows literals of given kind.
+func (f FieldPolicy) allowsKind(kind LiteralKind) bool {
+	for _, allowed := range f.Kinds {
+		if allowed == kind {
+			return true
+		}
+	}
+
+	return len(f.Kinds) == 0
+}
+
+// allowsValue checks if the policy allows given value. Allowed values are converted
+// like the values of the builder, so `1` matches the literal `1` and `1.0`.
+func (f FieldPolicy) allowsValue(value interface{}) bool {
+	for _, allowed := range f.Values {
+		literal, err := builderLiteral(allowed)
+		if err != nil {
+			continue
+		}
+
+		if date, ok := value.(time.Time); ok {
+			if allowedDate, ok := literal.Value.(time.Time); ok && date.Equal(allowedDate) {
+				return true
+			}
+
+			continue
+		}
+
+		if valuesEqual(value, literal.Value) {
+			return true
+		}
+	}
+
+	return len(f.Values) == 0
+}
@@ -0,0 +1,281 @@
+package rsql
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// earthRadius is the radius of the earth in meters that MongoDB
+// uses to convert distances into radians for `$centerSphere`.
+const earthRadius = 6378100
+
+// geoArguments returns the number of arguments of the geospatial operators.
+func geoArguments(operator Operator) (int, bool) {
+	switch operator { //nolint:exhaustive
+	case NearOperator, WithinOperator:
+		return 3, true
+	case BoxOperator:
+		return 4, true
+	}
+
+	return 0, false
+}
+
+/*
+ * <geo_comparison>
+ *   | <geo_operator> "(" <literal_list> ")"
+ * .
+ */
+func (p *state) geoComparison(node *ComparisonNode) error {
+	operator, err := p.eat(GeoCompareOperatorType)
+	if err != nil {
+		return err
+	}
+
+	start, err := p.eat(ContextStartType)
+	if err != nil {
+		return err
+	}
+
+	literalList, err := p.literalList()
+	if err != nil {
+		return err
+	}
+
+	_, err = p.eat(ContextEndType)
+	if err != nil {
+		return err
+	}
+
+	node.Operator = Operator(operator.Value)
+	node.Argument = Literal{Value: literalList, Kind: ListLiteralKind, Position: start.Position}
+
+	return checkGeo(node)
+}
+
+// checkGeo checks the number and ranges of the arguments of a geospatial operator.
+// The arguments are longitudes and latitudes followed by the distance for `=near=`
+// and `=within=`. Placeholders of templates are checked when they are bound.
+func checkGeo(node *ComparisonNode) error {
+	count, ok := geoArguments(node.Operator)
+	if !ok {
+		return nil
+	}
+
+	items, _ := node.Argument.Value.([]Literal)
+	if len(items) != count {
+		return GeoArgumentError{
+			operator: node.Operator, reason: fmt.Sprintf("expected %d numbers", count), position: node.Argument.Position,
+		}
+	}
+
+	for index, item := range items {
+		if item.Kind == PlaceholderLiteralKind {
+			continue
+		}
+
+		number, ok := geoNumber(item.Value)
+		distance := node.Operator != BoxOperator && index == 2
+		longitude := !distance && index%2 == 0
+
+		var reason string
+
+		switch {
+		case !ok:
+			reason = fmt.Sprintf("'%v' is not a number", item.Value)
+		case distance:
+			if number >= 0 {
+				continue
+			}
+
+			reason = fmt.Sprintf("distance '%v' is negative", item.Value)
+		case longitude:
+			if number >= -180 && number <= 180 {
+				continue
+			}
+
+			reason = fmt.Sprintf("longitude '%v' is out of range [-180, 180]", item.Value)
+		default:
+			if number >= -90 && number <= 90 {
+				continue
+			}
+
+			reason = fmt.Sprintf("latitude '%v' is out of range [-90, 90]", item.Value)
+		}
+
+		return GeoArgumentError{operator: node.Operator, reason: reason, position: item.Position}
+	}
+
+	return nil
+}
+
+// checkNear checks that a query has at most one `=near=`, that it is not nested in OR,
+// NOT or `=em=` and not combined with a text search since MongoDB rejects `$nearSphere` there.
+func checkNear(node Node) error {
+	found := false
+
+	if position, reason := findTopLevel(node, NearOperator, false, &found); reason != "" {
+		return OperatorPlacementError{operator: NearOperator, reason: reason, position: position}
+	}
+
+	if near := findComparison(node, NearOperator); near != nil && findComparison(node, TextOperator) != nil {
+		return OperatorPlacementError{
+			operator: NearOperator, reason: "can not be combined with " + string(TextOperator), position: near.Position,
+		}
+	}
+
+	return nil
+}
+
+// geoNumber converts the argument of a geospatial operator into a `float64`.
+func geoNumber(value interface{}) (float64, bool) {
+	if decimal, ok := value.(primitive.Decimal128); ok {
+		number, err := strconv.ParseFloat(decimal.String(), float64Size)
+
+		return number, err == nil
+	}
+
+	return toFloat(value)
+}
+
+// geoComparison converts a geospatial comparison into a filter element.
+func (e *MongoEmitter) geoComparison(node *ComparisonNode) (bson.E, error) {
+	items, _ := node.Argument.Value.([]Literal)
+	numbers := make([]float64, 0, len(items))
+
+	for _, item := range items {
+		number, ok := geoNumber(item.Value)
+		if !ok {
+			return bson.E{}, GeoArgumentError{
+				operator: node.Operator, reason: fmt.Sprintf("'%v' is not a number", item.Value), position: item.Position,
+			}
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	if count, _ := geoArguments(node.Operator); len(numbers) != count {
+		return bson.E{}, GeoArgumentError{
+			operator: node.Operator, reason: fmt.Sprintf("expected %d numbers", count), position: node.Argument.Position,
+		}
+	}
+
+	switch node.Operator { //nolint:exhaustive
+	case NearOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$nearSphere", Value: bson.D{
+			bson.E{Key: "$geometry", Value: bson.D{
+				bson.E{Key: "type", Value: "Point"},
+				bson.E{Key: "coordinates", Value: bson.A{numbers[0], numbers[1]}},
+			}},
+			bson.E{Key: "$maxDistance", Value: numbers[2]},
+		}}}}, nil
+	case WithinOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$geoWithin", Value: bson.D{
+			bson.E{Key: "$centerSphere", Value: bson.A{bson.A{numbers[0], numbers[1]}, numbers[2] / earthRadius}},
+		}}}}, nil
+	}
+
+	return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$geoWithin", Value: bson.D{
+		bson.E{Key: "$box", Value: bson.A{bson.A{numbers[0], numbers[1]}, bson.A{numbers[2], numbers[3]}}},
+	}}}}, nil
+}
+
+// decodeGeo converts a `$nearSphere` or `$geoWithin` created by the emitter into a geospatial comparison.
+func decodeGeo(field string, element bson.E) (Node, error) {
+	unsupported := UnsupportedFilterError{key: element.Key}
+
+	clause, ok := element.Value.(bson.D)
+	if !ok || len(clause) == 0 {
+		return nil, unsupported
+	}
+
+	var (
+		operator Operator
+		numbers  []float64
+	)
+
+	switch {
+	case element.Key == "$nearSphere" && len(clause) == 2 &&
+		clause[0].Key == "$geometry" && clause[1].Key == "$maxDistance":
+		point, ok := decodeGeoPoint(clause[0].Value)
+		distance, isNumber := geoNumber(clause[1].Value)
+
+		if !ok || !isNumber {
+			return nil, unsupported
+		}
+
+		operator, numbers = NearOperator, append(point, distance)
+	case element.Key == "$geoWithin" && len(clause) == 1 && clause[0].Key == "$centerSphere":
+		circle, ok := clause[0].Value.(bson.A)
+		if !ok || len(circle) != 2 {
+			return nil, unsupported
+		}
+
+		center, ok := geoCoordinates(circle[0])
+		radius, isNumber := geoNumber(circle[1])
+
+		if !ok || !isNumber {
+			return nil, unsupported
+		}
+
+		operator, numbers = WithinOperator, append(center, radius*earthRadius)
+	case element.Key == "$geoWithin" && len(clause) == 1 && clause[0].Key == "$box":
+		corners, ok := clause[0].Value.(bson.A)
+		if !ok || len(corners) != 2 {
+			return nil, unsupported
+		}
+
+		bottomLeft, ok := geoCoordinates(corners[0])
+		upperRight, isCorner := geoCoordinates(corners[1])
+
+		if !ok || !isCorner {
+			return nil, unsupported
+		}
+
+		operator, numbers = BoxOperator, append(bottomLeft, upperRight...)
+	default:
+		return nil, unsupported
+	}
+
+	items := make([]Literal, 0, len(numbers))
+	for _, number := range numbers {
+		items = append(items, Literal{Kind: FloatLiteralKind, Value: number})
+	}
+
+	node := &ComparisonNode{
+		Field: field, Operator: operator, Argument: Literal{Kind: ListLiteralKind, Value: items},
+	}
+
+	if err := checkGeo(node); err != nil {
+		return nil, unsupported
+	}
+
+	return node, nil
+}
+
+// decodeGeoPoint converts a GeoJSON point into its longitude and latitude.
+func decodeGeoPoint(value interface{}) ([]float64, bool) {
+	point, ok := value.(bson.D)
+	if !ok || len(point) != 2 || point[0].Key != "type" || point[0].Value != "Point" ||
+		point[1].Key != "coordinates" {
+		return nil, false
+	}
+
+	return geoCoordinates(point[1].Value)
+}
+
+// geoCoordinates converts a coordinate pair into its longitude and latitude.
+func geoCoordinates(value interface{}) ([]float64, bool) {
+	pair, ok := value.(bson.A)
+	if !ok || len(pair) != 2 {
+		return nil, false
+	}
+
+	longitude, isLongitude := geoNumber(pair[0])
+	latitude, isLatitude := geoNumber(pair[1])
+
+	return []float64{longitude, latitude}, isLongitude && isLatitude
+}
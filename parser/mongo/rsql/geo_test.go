@@ -0,0 +1,154 @@
+package rsql
+
+import (
+	"reflect"
+	"testing"
+
+	testutil "github.com/StevenCyb/goapiutils/parser/mongo/test_util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+//nolint:funlen
+func TestQueryParsingWithGeoOperators(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Near_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`location=near=(13.4,52.52,1000)`,
+			bson.D{bson.E{Key: "location", Value: bson.D{bson.E{Key: "$nearSphere", Value: bson.D{
+				bson.E{Key: "$geometry", Value: bson.D{
+					bson.E{Key: "type", Value: "Point"},
+					bson.E{Key: "coordinates", Value: bson.A{13.4, 52.52}},
+				}},
+				bson.E{Key: "$maxDistance", Value: float64(1000)},
+			}}}}},
+		)
+	})
+
+	t.Run("Within_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`location=within=(-73.97,40.77,6378.1)`,
+			bson.D{bson.E{Key: "location", Value: bson.D{bson.E{Key: "$geoWithin", Value: bson.D{
+				bson.E{Key: "$centerSphere", Value: bson.A{bson.A{-73.97, 40.77}, 0.001}},
+			}}}}},
+		)
+	})
+
+	t.Run("Box_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`location=box=(0,0,100,90)`,
+			bson.D{bson.E{Key: "location", Value: bson.D{bson.E{Key: "$geoWithin", Value: bson.D{
+				bson.E{Key: "$box", Value: bson.A{bson.A{float64(0), float64(0)}, bson.A{float64(100), float64(90)}}},
+			}}}}},
+		)
+	})
+
+	t.Run("WithDecimals_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil).SetNumericOptions(NumericOptions{Decimal: true}),
+			`location=box=(0.5,0,1,1)`,
+			bson.D{bson.E{Key: "location", Value: bson.D{bson.E{Key: "$geoWithin", Value: bson.D{
+				bson.E{Key: "$box", Value: bson.A{bson.A{0.5, float64(0)}, bson.A{float64(1), float64(1)}}},
+			}}}}},
+		)
+	})
+
+	t.Run("WithTemplate_Success", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).Prepare(`location=near=(:lng,:lat,500)`)
+		require.NoError(t, err)
+
+		_, err = template.Bind(map[string]interface{}{"lng": 13.4, "lat": 52.52})
+		require.NoError(t, err)
+
+		_, err = template.Bind(map[string]interface{}{"lng": 13.4, "lat": 91})
+		require.Equal(t, GeoArgumentError{
+			operator: NearOperator, reason: "latitude '91' is out of range [-90, 90]", position: 20,
+		}, err)
+
+		_, err = template.Bind(map[string]interface{}{"lng": "13.4", "lat": 52.52})
+		require.Equal(t, ParameterTypeError{name: "lng", operator: NearOperator, valueType: "string", position: 15}, err)
+	})
+
+	t.Run("WithSmartParser_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+		require.NoError(t, err)
+
+		_, err = parser.Parse(`attributes=near=(13.4,52.52,1000)`)
+		require.NoError(t, err)
+
+		_, err = parser.Parse(`name=near=(13.4,52.52,1000)`)
+		require.Equal(t, OperatorMismatchError{field: "name", operator: NearOperator, fieldType: "string"}, err)
+	})
+
+	t.Run("Placement_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]OperatorPlacementError{
+			`loc=near=(1,2,100),a==1`: {operator: NearOperator, reason: "can not be nested in OR, NOT or =em=", position: 0},
+			`!(loc=near=(1,2,100))`:   {operator: NearOperator, reason: "can not be nested in OR, NOT or =em=", position: 2},
+			`arr=em=(loc=near=(1,2,100))`: {
+				operator: NearOperator, reason: "can not be nested in OR, NOT or =em=", position: 8,
+			},
+			`loc=near=(1,2,100);loc2=near=(1,2,100)`: {
+				operator: NearOperator, reason: "can only be used once", position: 19,
+			},
+			`*=text="x";loc=near=(1,2,3)`: {operator: NearOperator, reason: "can not be combined with =text=", position: 11},
+			`(loc=near=(1,2,3));*=text="x"`: {
+				operator: NearOperator, reason: "can not be combined with =text=", position: 1,
+			},
+		} {
+			_, err := NewParser(nil).Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+
+		_, err := NewParser(nil).Parse(`(a==1;loc=near=(1,2,100));loc=within=(1,2,100)`)
+		require.NoError(t, err)
+	})
+
+	t.Run("PlacementWithTemplate_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).Prepare(`loc=near=(:lng,:lat,100),a==1`)
+		require.Equal(t, OperatorPlacementError{
+			operator: NearOperator, reason: "can not be nested in OR, NOT or =em=", position: 0,
+		}, err)
+		require.Nil(t, template)
+	})
+
+	t.Run("Arguments_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]GeoArgumentError{
+			`l=near=(1,2)`:     {operator: NearOperator, reason: "expected 3 numbers", position: 7},
+			`l=box=(1,2,3)`:    {operator: BoxOperator, reason: "expected 4 numbers", position: 6},
+			`l=near=("a",2,3)`: {operator: NearOperator, reason: "'a' is not a number", position: 8},
+			`l=near=(181,2,3)`: {
+				operator: NearOperator, reason: "longitude '181' is out of range [-180, 180]", position: 8,
+			},
+			`l=within=(1,-90.5,3)`: {
+				operator: WithinOperator, reason: "latitude '-90.5' is out of range [-90, 90]", position: 12,
+			},
+			`l=within=(1,2,-3)`: {operator: WithinOperator, reason: "distance '-3' is negative", position: 14},
+			`l=box=(0,0,1,95)`:  {operator: BoxOperator, reason: "latitude '95' is out of range [-90, 90]", position: 13},
+		} {
+			_, err := NewParser(nil).Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+}
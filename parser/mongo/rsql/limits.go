@@ -0,0 +1,35 @@
+package rsql
+
+import "github.com/StevenCyb/goapiutils/parser/errs"
+
+// Names of the limits that are used in errors.
+const (
+	MaxDepthLimit       = "MAX_DEPTH"
+	MaxComparisonsLimit = "MAX_COMPARISONS"
+	MaxListLengthLimit  = "MAX_LIST_LENGTH"
+	MaxQueryLengthLimit = "MAX_QUERY_LENGTH"
+	MaxRegexLengthLimit = "MAX_REGEX_LENGTH"
+)
+
+// Limits restricts the complexity of queries. A limit of zero is disabled.
+type Limits struct {
+	// MaxDepth is the maximum nesting of contexts, negations and `=em=`.
+	MaxDepth int
+	// MaxComparisons is the maximum number of comparisons.
+	MaxComparisons int
+	// MaxListLength is the maximum number of literals in a list e.g. of `=in=`.
+	MaxListLength int
+	// MaxQueryLength is the maximum length of the query in bytes.
+	MaxQueryLength int
+	// MaxRegexLength is the maximum length of literals of string operators like `=like=`.
+	MaxRegexLength int
+}
+
+// check returns an error if a value exceeds the limit.
+func check(limit string, max, value, position int) error {
+	if max > 0 && value > max {
+		return errs.NewErrLimitExceeded(position, limit, max)
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package rsql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMongoEmitter creates a new emitter for MongoDB filters.
+func NewMongoEmitter() *MongoEmitter {
+	return &MongoEmitter{
+		operators: map[Operator]OperatorHandler{},
+	}
+}
+
+// MongoEmitter converts an abstract syntax tree into a MongoDB filter.
+type MongoEmitter struct {
+	operators       map[Operator]OperatorHandler
+	numericVariants bool
+	textSearch      TextSearchOptions
+}
+
+// RegisterOperator register the handler of a custom comparison operator.
+func (e *MongoEmitter) RegisterOperator(operator Operator, handler OperatorHandler) {
+	e.operators[operator] = handler
+}
+
+// Emit converts a given node into a MongoDB filter.
+// A nil node results in an empty filter.
+func (e *MongoEmitter) Emit(node Node) (bson.D, error) {
+	if node == nil {
+		return bson.D{}, nil
+	}
+
+	element, err := e.element(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.D{element}, nil
+}
+
+// element converts a node into a single filter element.
+func (e *MongoEmitter) element(node Node) (bson.E, error) {
+	switch node := node.(type) {
+	case *AndNode:
+		return e.composite("$and", node.Children)
+	case *OrNode:
+		return e.composite("$or", node.Children)
+	case *GroupNode:
+		return e.element(node.Child)
+	case *ComparisonNode:
+		return e.comparison(node)
+	case *NotNode:
+		return e.negation(node)
+	case *ElemMatchNode:
+		child, err := e.Emit(node.Child)
+		if err != nil {
+			return bson.E{}, err
+		}
+
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$elemMatch", Value: child}}}, nil
+	}
+
+	return bson.E{}, errs.NewErrUnexpectedInput(node)
+}
+
+// composite converts children into a logical operation. Composites of the
+// same kind on the right-hand side are merged into a single operation.
+func (e *MongoEmitter) composite(key string, children []Node) (bson.E, error) {
+	if len(children) == 0 {
+		return bson.E{}, errs.NewErrUnexpectedInputEnd(FieldNameType.String())
+	}
+
+	right, err := e.element(children[len(children)-1])
+	if err != nil {
+		return bson.E{}, err
+	}
+
+	for i := len(children) - 2; i >= 0; i-- {
+		left, err := e.element(children[i])
+		if err != nil {
+			return bson.E{}, err
+		}
+
+		if right.Key != key {
+			right = bson.E{Key: key, Value: bson.A{bson.D{left}, bson.D{right}}}
+
+			continue
+		}
+
+		items, ok := right.Value.(bson.A)
+		if !ok {
+			return bson.E{}, errs.NewErrUnexpectedInput(right.Value)
+		}
+
+		right = bson.E{Key: key, Value: append(bson.A{bson.D{left}}, items...)}
+	}
+
+	return right, nil
+}
+
+// negation converts a not node into a filter element. Operator expressions
+// and regular expressions of a single field are negated with `$not`,
+// anything else with `$nor`.
+func (e *MongoEmitter) negation(node *NotNode) (bson.E, error) {
+	child, err := e.element(node.Child)
+	if err != nil {
+		return bson.E{}, err
+	}
+
+	if !strings.HasPrefix(child.Key, "$") && isOperatorExpression(child.Value) {
+		return bson.E{Key: child.Key, Value: bson.D{bson.E{Key: "$not", Value: child.Value}}}, nil
+	}
+
+	return bson.E{Key: "$nor", Value: bson.A{bson.D{child}}}, nil
+}
+
+// isOperatorExpression checks if a value is a regular expression or a
+// document that only contains operators (except `$not` that can't be nested).
+func isOperatorExpression(value interface{}) bool {
+	switch value := value.(type) {
+	case primitive.Regex:
+		return true
+	case bson.D:
+		for _, element := range value {
+			if !strings.HasPrefix(element.Key, "$") || element.Key == "$not" {
+				return false
+			}
+		}
+
+		return len(value) > 0
+	}
+
+	return false
+}
+
+// comparison converts a comparison node into a filter element.
+func (e *MongoEmitter) comparison(node *ComparisonNode) (bson.E, error) {
+	value := e.value(node.Argument)
+
+	if e.numericVariants {
+		if element, ok := e.numericComparison(node, value); ok {
+			return element, nil
+		}
+	}
+
+	switch node.Operator {
+	case EqualOperator:
+		return bson.E{Key: node.Field, Value: value}, nil
+	case NotEqualOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$ne", Value: value}}}, nil
+	case GreaterThanOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$gt", Value: value}}}, nil
+	case GreaterThanOrEqualOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$gte", Value: value}}}, nil
+	case LessThanOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$lt", Value: value}}}, nil
+	case LessThanOrEqualOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$lte", Value: value}}}, nil
+	case InOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$in", Value: value}}}, nil
+	case NotInOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$nin", Value: value}}}, nil
+	case AllOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$all", Value: value}}}, nil
+	case SizeOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$size", Value: value}}}, nil
+	case ExistsOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$exists", Value: value}}}, nil
+	case TextOperator:
+		return e.textSearchClause(value), nil
+	case NearOperator, WithinOperator, BoxOperator:
+		return e.geoComparison(node)
+	case NullOperator:
+		isNull := bson.D{bson.E{Key: "$type", Value: "null"}}
+		if value == true {
+			return bson.E{Key: node.Field, Value: isNull}, nil
+		}
+
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$not", Value: isNull}}}, nil
+	}
+
+	if literal, ok := value.(string); ok {
+		if regex, ok := Regex(node.Operator, literal); ok {
+			return bson.E{Key: node.Field, Value: regex}, nil
+		}
+	}
+
+	if handler, exists := e.operators[node.Operator]; exists {
+		if node.Argument.Kind == ListLiteralKind {
+			args, _ := value.(bson.A)
+
+			return handler(node.Field, args)
+		}
+
+		return handler(node.Field, []interface{}{value})
+	}
+
+	return bson.E{}, errs.NewErrUnexpectedToken(node.Position, string(node.Operator))
+}
+
+// numericComparison converts comparisons on numbers into `$in` or `$nin` over the
+// numeric variants of the numbers. The result is false for other comparisons.
+func (e *MongoEmitter) numericComparison(node *ComparisonNode, value interface{}) (bson.E, bool) {
+	switch node.Operator { //nolint:exhaustive
+	case EqualOperator, NotEqualOperator:
+		variants, ok := numericVariants(value)
+		if !ok {
+			return bson.E{}, false
+		}
+
+		if node.Operator == EqualOperator {
+			return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$in", Value: variants}}}, true
+		}
+
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$nin", Value: variants}}}, true
+	case InOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$in", Value: expandNumericVariants(value)}}}, true
+	case NotInOperator:
+		return bson.E{Key: node.Field, Value: bson.D{bson.E{Key: "$nin", Value: expandNumericVariants(value)}}}, true
+	}
+
+	return bson.E{}, false
+}
+
+// value converts a literal into its MongoDB representation.
+func (e *MongoEmitter) value(literal Literal) interface{} {
+	if date, ok := literal.Value.(time.Time); ok {
+		return primitive.NewDateTimeFromTime(date)
+	}
+
+	if literal.Kind != ListLiteralKind {
+		return literal.Value
+	}
+
+	items, _ := literal.Value.([]Literal)
+	values := make(bson.A, 0, len(items))
+
+	for _, item := range items {
+		values = append(values, e.value(item))
+	}
+
+	return values
+}
@@ -0,0 +1,64 @@
+package rsql
+
+import (
+	"testing"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMongoEmitter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithNilNode_Success", func(t *testing.T) {
+		t.Parallel()
+
+		filter, err := NewMongoEmitter().Emit(nil)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{}, filter)
+	})
+
+	t.Run("WithRewrittenTree_Success", func(t *testing.T) {
+		t.Parallel()
+
+		node, err := NewParser(nil).ParseAST(`age=ge=18;name=="steven"`)
+		require.NoError(t, err)
+
+		and, ok := node.(*AndNode)
+		require.True(t, ok)
+
+		comparison, ok := and.Children[1].(*ComparisonNode)
+		require.True(t, ok)
+
+		comparison.Field = "first_name"
+
+		filter, err := NewMongoEmitter().Emit(node)
+		require.NoError(t, err)
+		require.Equal(t,
+			bson.D{
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{
+						bson.E{Key: "age", Value: bson.D{
+							bson.E{Key: "$gte", Value: int64(18)},
+						}},
+					},
+					bson.D{
+						bson.E{Key: "first_name", Value: "steven"},
+					},
+				}},
+			},
+			filter,
+		)
+	})
+
+	t.Run("WithUnknownOperator_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewMongoEmitter().Emit(&ComparisonNode{
+			Field: "a", Operator: "=unknown=", Position: 3,
+			Argument: Literal{Value: int64(1), Kind: IntLiteralKind},
+		})
+		require.Equal(t, errs.NewErrUnexpectedToken(3, "=unknown="), err)
+	})
+}
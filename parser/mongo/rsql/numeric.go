@@ -0,0 +1,201 @@
+package rsql
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IntWidth is the type integer literals are converted into.
+type IntWidth byte
+
+const (
+	// Int64Width converts integer literals into `int64`.
+	Int64Width IntWidth = iota
+	// Int32Width converts integer literals into `int32` and rejects integers that overflow it.
+	Int32Width
+	// AutoIntWidth converts integer literals into `int32` if they fit and into `int64` otherwise.
+	AutoIntWidth
+)
+
+// NumericOptions configures the syntax and types of numeric literals.
+// The zero value accepts integers and floats like `12` and `1.5`.
+type NumericOptions struct {
+	// IntWidth is the type of integer literals, the default is `Int64Width`.
+	IntWidth IntWidth
+	// Decimal converts float literals into `primitive.Decimal128`, e.g. for money values.
+	// Literals like `$dec(12.50)` are converted into `primitive.Decimal128` regardless of this option.
+	Decimal bool
+	// Exponent accepts literals in exponent notation like `1.5e3` that are floats.
+	Exponent bool
+	// Hex accepts integer literals in hexadecimal notation like `0x1F`.
+	Hex bool
+	// MixedTypes emits `==`, `!=`, `=in=` and `=out=` on numbers as `$in`/`$nin` over the `int32`,
+	// `int64`, `float64` and `primitive.Decimal128` representations of the numbers, so documents
+	// of collections with mixed numeric types match.
+	MixedTypes bool
+}
+
+// numberExpression returns the expression of numeric literals without anchors.
+func numberExpression(options NumericOptions) string {
+	expression := `\d+(\.\d+)?`
+	if options.Exponent {
+		expression += `([eE](-|\+)?\d+)?`
+	}
+
+	if options.Hex {
+		expression = `0[xX][0-9a-fA-F]+|` + expression
+	}
+
+	return `(-|\+)?(?:` + expression + `)`
+}
+
+// numericValue converts a numeric literal into the type given by the options.
+func numericValue(number string, options NumericOptions) (interface{}, LiteralKind, error) {
+	unsigned := strings.TrimLeft(number, "+-")
+
+	switch {
+	case strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X"):
+		// base 0 is only used for hexadecimal literals since it parses `017` as octal
+		value, err := strconv.ParseInt(number, 0, int64Size)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "failed to parse int value")
+		}
+
+		return intValue(value, options.IntWidth)
+	case strings.ContainsAny(number, ".eE"):
+		if options.Decimal {
+			return decimalValue(number)
+		}
+
+		value, err := strconv.ParseFloat(number, float64Size)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "failed to parse float value")
+		}
+
+		return value, FloatLiteralKind, nil
+	}
+
+	value, err := strconv.ParseInt(number, intBase, int64Size)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse int value")
+	}
+
+	return intValue(value, options.IntWidth)
+}
+
+// intValue converts an integer into the type of given width.
+func intValue(value int64, width IntWidth) (interface{}, LiteralKind, error) {
+	fitsInt32 := value >= math.MinInt32 && value <= math.MaxInt32
+
+	switch {
+	case width == Int32Width && !fitsInt32:
+		return nil, "", errors.Errorf("int value '%d' overflows int32", value)
+	case width == Int32Width, width == AutoIntWidth && fitsInt32:
+		return int32(value), IntLiteralKind, nil
+	}
+
+	return value, IntLiteralKind, nil
+}
+
+// decimalValue converts a number into a `primitive.Decimal128`.
+func decimalValue(number string) (interface{}, LiteralKind, error) {
+	value, err := primitive.ParseDecimal128(number)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse decimal value")
+	}
+
+	return value, DecimalLiteralKind, nil
+}
+
+// numericVariants returns the representations of a number as `int32`, `int64`, `float64`
+// and `primitive.Decimal128` that are equal to it. Floats are represented by the shortest
+// decimal that converts back into the float. The result is false for other values.
+//
+//nolint:cyclop
+func numericVariants(value interface{}) (bson.A, bool) {
+	var number string
+
+	switch value := value.(type) {
+	case int32:
+		number = strconv.FormatInt(int64(value), intBase)
+	case int64:
+		number = strconv.FormatInt(value, intBase)
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, false
+		}
+
+		number = strconv.FormatFloat(value, 'f', -1, float64Size)
+	case primitive.Decimal128:
+		number = value.String()
+	default:
+		return nil, false
+	}
+
+	variants := bson.A{}
+
+	if integer, ok := integerValue(number); ok {
+		if integer >= math.MinInt32 && integer <= math.MaxInt32 {
+			variants = append(variants, int32(integer))
+		}
+
+		variants = append(variants, integer)
+
+		if float := float64(integer); float >= -(1<<53) && float <= 1<<53 {
+			variants = append(variants, float)
+		}
+	} else if float, err := strconv.ParseFloat(number, float64Size); err == nil && !math.IsInf(float, 0) {
+		variants = append(variants, float)
+	}
+
+	if decimal, err := primitive.ParseDecimal128(number); err == nil {
+		variants = append(variants, decimal)
+	}
+
+	return variants, len(variants) > 0
+}
+
+// integerValue returns the integer of a number without fraction like `12`, `12.0` or `1.5E+3`.
+func integerValue(number string) (int64, bool) {
+	if strings.Contains(number, ".") && !strings.ContainsAny(number, "eE") {
+		number = strings.TrimRight(strings.TrimRight(number, "0"), ".")
+	}
+
+	if integer, err := strconv.ParseInt(number, intBase, int64Size); err == nil {
+		return integer, true
+	}
+
+	float, err := strconv.ParseFloat(number, float64Size)
+	if err != nil || float != math.Trunc(float) || float < -(1<<53) || float > 1<<53 {
+		return 0, false
+	}
+
+	return int64(float), true
+}
+
+// expandNumericVariants replaces the numbers of a list by their variants.
+func expandNumericVariants(values interface{}) interface{} {
+	items, ok := values.(bson.A)
+	if !ok {
+		return values
+	}
+
+	expanded := make(bson.A, 0, len(items))
+
+	for _, item := range items {
+		if variants, ok := numericVariants(item); ok {
+			expanded = append(expanded, variants...)
+
+			continue
+		}
+
+		expanded = append(expanded, item)
+	}
+
+	return expanded
+}
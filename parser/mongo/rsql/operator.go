@@ -0,0 +1,75 @@
+package rsql
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ArgKind represent the kind of arguments an operator takes.
+type ArgKind byte
+
+const (
+	// LiteralArg declare an operator
+	// that takes a single literal.
+	LiteralArg ArgKind = 0
+	// LiteralListArg declare an operator that takes
+	// a list of literals in round brackets.
+	LiteralListArg ArgKind = 1
+)
+
+// OperatorHandler creates the filter element for a custom operator.
+// The arguments contain the literal values in order of appearance.
+type OperatorHandler func(field string, args []interface{}) (bson.E, error)
+
+// customOperator is a registered custom operator.
+type customOperator struct {
+	handler OperatorHandler
+	argKind ArgKind
+}
+
+// operatorFormat is the format of custom operators.
+//
+//nolint:gochecknoglobals
+var operatorFormat = regexp.MustCompile(`^=[a-zA-Z]+=$`)
+
+// builtinOperators lists the operators that are part of the grammar.
+//
+//nolint:gochecknoglobals
+var builtinOperators = []Operator{
+	EqualOperator, NotEqualOperator,
+	GreaterThanOperator, GreaterThanOrEqualOperator, LessThanOperator, LessThanOrEqualOperator,
+	StartsWithOperator, EndsWithOperator,
+	InOperator, NotInOperator,
+	LikeOperator, ContainsOperator,
+	CaseInsensitiveStartsWithOperator, CaseInsensitiveEndsWithOperator, CaseInsensitiveEqualOperator,
+	ExistsOperator, NullOperator,
+	AllOperator, SizeOperator, ElemMatchOperator, TextOperator,
+	NearOperator, WithinOperator, BoxOperator,
+}
+
+// isBuiltinOperator checks if given operator is part of the grammar.
+func isBuiltinOperator(operator Operator) bool {
+	for _, builtin := range builtinOperators {
+		if builtin == operator {
+			return true
+		}
+	}
+
+	return false
+}
+
+// operatorExpression creates the tokenizer expression
+// that matches any of given operators.
+func operatorExpression(operators map[Operator]customOperator) string {
+	names := make([]string, 0, len(operators))
+	for operator := range operators {
+		names = append(names, regexp.QuoteMeta(string(operator)))
+	}
+
+	sort.Strings(names)
+
+	return "^(" + strings.Join(names, "|") + ")"
+}
@@ -0,0 +1,138 @@
+package rsql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	testutil "github.com/StevenCyb/goapiutils/parser/mongo/test_util"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var errDivisorIsZero = errors.New("divisor is zero")
+
+func modHandler(field string, args []interface{}) (bson.E, error) {
+	if args[0] == int64(0) {
+		return bson.E{}, errDivisorIsZero
+	}
+
+	return bson.E{Key: field, Value: bson.D{bson.E{Key: "$mod", Value: bson.A(args)}}}, nil
+}
+
+func regexHandler(field string, args []interface{}) (bson.E, error) {
+	return bson.E{Key: field, Value: bson.D{bson.E{Key: "$regex", Value: args[0]}}}, nil
+}
+
+func TestRegisterOperator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithInvalidFormat_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		parser := NewParser(nil)
+		require.ErrorIs(t, parser.RegisterOperator("mod", LiteralListArg, modHandler), ErrInvalidOperator)
+		require.ErrorIs(t, parser.RegisterOperator("=m o d=", LiteralListArg, modHandler), ErrInvalidOperator)
+	})
+
+	t.Run("WithDuplicate_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		parser := NewParser(nil)
+		require.NoError(t, parser.RegisterOperator("=mod=", LiteralListArg, modHandler))
+		require.ErrorIs(t, parser.RegisterOperator("=mod=", LiteralListArg, modHandler), ErrDuplicateOperator)
+		require.ErrorIs(t, parser.RegisterOperator("=gt=", LiteralArg, regexHandler), ErrDuplicateOperator)
+	})
+
+	t.Run("WithNilHandler_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, NewParser(nil).RegisterOperator("=mod=", LiteralListArg, nil), ErrNilHandler)
+	})
+}
+
+func TestQueryParsingWithCustomOperator(t *testing.T) {
+	t.Parallel()
+
+	newParser := func(t *testing.T, policy *tokenizer.Policy) *Parser {
+		t.Helper()
+
+		parser := NewParser(policy)
+		require.NoError(t, parser.RegisterOperator("=mod=", LiteralListArg, modHandler))
+		require.NoError(t, parser.RegisterOperator("=regex=", LiteralArg, regexHandler))
+
+		return parser
+	}
+
+	t.Run("WithLiteralList_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			newParser(t, nil),
+			`qty=mod=(4,0);name=="x"`,
+			bson.D{
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{
+						bson.E{Key: "qty", Value: bson.D{
+							bson.E{Key: "$mod", Value: bson.A{int64(4), int64(0)}},
+						}},
+					},
+					bson.D{
+						bson.E{Key: "name", Value: "x"},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithLiteral_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			newParser(t, nil),
+			`name=regex="^a"`,
+			bson.D{bson.E{Key: "name", Value: bson.D{bson.E{Key: "$regex", Value: "^a"}}}},
+		)
+	})
+
+	t.Run("WithHandlerError_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			newParser(t, nil),
+			`qty=mod=(0,1)`,
+			errDivisorIsZero,
+		)
+	})
+
+	t.Run("WithMissingList_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			newParser(t, nil),
+			`qty=mod=4`,
+			errs.NewErrUnexpectedTokenType(9, "NUMERIC_LITERAL", "("),
+		)
+	})
+
+	t.Run("WithUnregisteredOperator_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`qty=mod=(4,0)`,
+			errs.NewErrUnexpectedToken(3, "="),
+		)
+	})
+
+	t.Run("WithDisallowedFieldName_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			newParser(t, tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name")),
+			`qty=mod=(4,0)`,
+			errs.NewErrPolicyViolation("qty"),
+		)
+	})
+}
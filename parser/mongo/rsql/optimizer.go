@@ -0,0 +1,719 @@
+package rsql
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Optimize normalizes a filter created by the parser. Nested `$and`/`$or` are flattened,
+// range conditions on the same field are merged into one document with the tightest bounds,
+// equalities of a field joined by `$or` are collapsed into `$in` and duplicates are removed.
+// Filters that can never match result in a `ContradictionError`. Since any field may be
+// an array, only contradictions that also hold for arrays are detected (e.g. `a==1;a!=1`).
+func Optimize(filter bson.D) (bson.D, error) {
+	return optimizer{}.filter(filter)
+}
+
+// optimizer optimizes filters. Scalar reports fields that
+// can't be arrays, negated disables the contradiction checks.
+type optimizer struct {
+	scalar  func(field string) bool
+	negated bool
+}
+
+// filter optimizes a filter and joins its conditions with `$and` if there is more than one.
+func (o optimizer) filter(filter bson.D) (bson.D, error) {
+	conditions, err := o.conjunction(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return joinConditions(conditions), nil
+}
+
+// joinConditions creates a filter from conditions that must all match.
+func joinConditions(conditions []bson.E) bson.D {
+	switch len(conditions) {
+	case 0:
+		return bson.D{}
+	case 1:
+		return bson.D{conditions[0]}
+	}
+
+	items := make(bson.A, 0, len(conditions))
+	for _, condition := range conditions {
+		items = append(items, bson.D{condition})
+	}
+
+	return bson.D{bson.E{Key: "$and", Value: items}}
+}
+
+// conjunction returns the optimized conditions of a filter that must all match.
+func (o optimizer) conjunction(filter bson.D) ([]bson.E, error) {
+	conditions := []bson.E{}
+
+	if err := o.collect(filter, &conditions); err != nil {
+		return nil, err
+	}
+
+	conditions = mergeRanges(dedupe(conditions))
+
+	return conditions, o.checkContradictions(conditions)
+}
+
+// collect appends the optimized conditions of a filter and flattens nested `$and`.
+func (o optimizer) collect(filter bson.D, conditions *[]bson.E) error {
+	for _, element := range filter {
+		if element.Key == "$and" {
+			if items, ok := filterList(element.Value); ok {
+				for _, item := range items {
+					if err := o.collect(item, conditions); err != nil {
+						return err
+					}
+				}
+
+				continue
+			}
+		}
+
+		optimized, err := o.element(element)
+		if err != nil {
+			return err
+		}
+
+		*conditions = append(*conditions, optimized...)
+	}
+
+	return nil
+}
+
+// element optimizes a single filter element. A disjunction
+// with a single remaining branch results in multiple conditions.
+func (o optimizer) element(element bson.E) ([]bson.E, error) {
+	switch element.Key {
+	case "$or":
+		return o.disjunction(element)
+	case "$nor":
+		items, ok := filterList(element.Value)
+		if !ok {
+			break
+		}
+
+		// a branch of `$nor` that can't match doesn't make the filter contradict
+		negated := optimizer{scalar: o.scalar, negated: true}
+		optimized := make(bson.A, 0, len(items))
+
+		for _, item := range items {
+			filter, err := negated.filter(item)
+			if err != nil {
+				return nil, err
+			}
+
+			optimized = append(optimized, filter)
+		}
+
+		return []bson.E{{Key: element.Key, Value: optimized}}, nil
+	}
+
+	if filter, ok := operand(element.Value, "$elemMatch"); ok {
+		if filter, ok := filter.(bson.D); ok {
+			// fields of `=em=` are relative to the array elements
+			optimized, err := optimizer{negated: o.negated}.filter(filter)
+			if err != nil {
+				return nil, err
+			}
+
+			return []bson.E{{Key: element.Key, Value: bson.D{bson.E{Key: "$elemMatch", Value: optimized}}}}, nil
+		}
+	}
+
+	return []bson.E{element}, nil
+}
+
+// disjunction optimizes the branches of `$or`. Branches that can't match are removed
+// and equalities of a field are collapsed into `$in`.
+func (o optimizer) disjunction(element bson.E) ([]bson.E, error) {
+	items, ok := filterList(element.Value)
+	if !ok {
+		return []bson.E{element}, nil
+	}
+
+	branches := [][]bson.E{}
+
+	var contradiction error
+
+	for _, item := range items {
+		conditions, err := o.conjunction(item)
+
+		var contradictionErr ContradictionError
+		if errors.As(err, &contradictionErr) {
+			contradiction = err
+
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// nested `$or` are already optimized and their branches are taken over
+		if len(conditions) == 1 && conditions[0].Key == "$or" {
+			nested, _ := filterList(conditions[0].Value)
+			for _, branch := range nested {
+				branches = append(branches, []bson.E(branch))
+			}
+
+			continue
+		}
+
+		branches = append(branches, conditions)
+	}
+
+	if len(branches) == 0 {
+		return nil, contradiction
+	}
+
+	branches = collapseEqualities(dedupeBranches(branches))
+	if len(branches) == 1 {
+		return branches[0], nil
+	}
+
+	optimized := make(bson.A, 0, len(branches))
+	for _, branch := range branches {
+		optimized = append(optimized, joinConditions(branch))
+	}
+
+	return []bson.E{{Key: "$or", Value: optimized}}, nil
+}
+
+// collapseEqualities merges the branches that compare the same field
+// with `==` or `=in=` into a single `$in` (with the shape of the emitter).
+func collapseEqualities(branches [][]bson.E) [][]bson.E {
+	collapsed := make([][]bson.E, 0, len(branches))
+	fields := map[string]int{}
+	merged := map[string]bool{}
+
+	for _, branch := range branches {
+		values, ok := equalityValues(branch)
+		if !ok {
+			collapsed = append(collapsed, branch)
+
+			continue
+		}
+
+		field := branch[0].Key
+
+		index, exists := fields[field]
+		if !exists {
+			fields[field] = len(collapsed)
+			collapsed = append(collapsed, branch)
+
+			continue
+		}
+
+		current, _ := equalityValues(collapsed[index])
+		list := appendMissing(current, values)
+		collapsed[index] = []bson.E{{Key: field, Value: bson.D{{Key: "$in", Value: list}}}}
+		merged[field] = true
+	}
+
+	for field := range merged {
+		index := fields[field]
+		if values, _ := equalityValues(collapsed[index]); len(values) == 1 {
+			collapsed[index] = []bson.E{{Key: field, Value: values[0]}}
+		}
+	}
+
+	return collapsed
+}
+
+// equalityValues returns the values of a branch that only consists of `==` or `=in=` on a field.
+func equalityValues(branch []bson.E) (bson.A, bool) {
+	if len(branch) != 1 || strings.HasPrefix(branch[0].Key, "$") {
+		return nil, false
+	}
+
+	if values, ok := listOperand(branch[0].Value, "$in"); ok {
+		return values, true
+	}
+
+	if isPlainValue(branch[0].Value) {
+		return bson.A{branch[0].Value}, true
+	}
+
+	return nil, false
+}
+
+// appendMissing appends the values that are not yet part of the list.
+func appendMissing(list, values bson.A) bson.A {
+	result := append(bson.A{}, list...)
+
+	for _, value := range values {
+		if !containsAll(result, bson.A{value}) {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// dedupe removes conditions that are equal to a previous one.
+func dedupe(conditions []bson.E) []bson.E {
+	unique := make([]bson.E, 0, len(conditions))
+
+	for _, condition := range conditions {
+		duplicate := false
+
+		for _, existing := range unique {
+			if reflect.DeepEqual(existing, condition) {
+				duplicate = true
+
+				break
+			}
+		}
+
+		if !duplicate {
+			unique = append(unique, condition)
+		}
+	}
+
+	return unique
+}
+
+// dedupeBranches removes branches that are equal to a previous one.
+func dedupeBranches(branches [][]bson.E) [][]bson.E {
+	unique := make([][]bson.E, 0, len(branches))
+
+	for _, branch := range branches {
+		duplicate := false
+
+		for _, existing := range unique {
+			if reflect.DeepEqual(existing, branch) {
+				duplicate = true
+
+				break
+			}
+		}
+
+		if !duplicate {
+			unique = append(unique, branch)
+		}
+	}
+
+	return unique
+}
+
+// mergeRanges merges the range conditions of a field into one document that keeps
+// the tightest bounds. Conditions with bounds that can't be compared are kept separately.
+func mergeRanges(conditions []bson.E) []bson.E {
+	merged := make([]bson.E, 0, len(conditions))
+	ranges := map[string]int{}
+
+	for _, condition := range conditions {
+		bounds, ok := rangeBounds(condition.Value)
+		if !ok || strings.HasPrefix(condition.Key, "$") {
+			merged = append(merged, condition)
+
+			continue
+		}
+
+		index, exists := ranges[condition.Key]
+		if !exists {
+			ranges[condition.Key] = len(merged)
+			merged = append(merged, condition)
+
+			continue
+		}
+
+		current, _ := merged[index].Value.(bson.D)
+		combined := append(bson.D{}, current...)
+
+		for _, bound := range bounds {
+			if combined, ok = addBound(combined, bound); !ok {
+				break
+			}
+		}
+
+		if !ok {
+			merged = append(merged, condition)
+
+			continue
+		}
+
+		merged[index] = bson.E{Key: condition.Key, Value: combined}
+	}
+
+	return merged
+}
+
+// addBound adds a bound to a range document or replaces
+// the bound of the same side if the new one is tighter.
+func addBound(bounds bson.D, bound bson.E) (bson.D, bool) {
+	lower := isLowerBound(bound.Key)
+
+	for i, existing := range bounds {
+		if isLowerBound(existing.Key) != lower {
+			continue
+		}
+
+		cmp, ok := compareValues(bound.Value, existing.Value)
+		if !ok {
+			return nil, false
+		}
+
+		if !lower {
+			cmp = -cmp
+		}
+
+		if cmp > 0 || (cmp == 0 && isExclusiveBound(bound.Key)) {
+			bounds[i] = bound
+		}
+
+		return bounds, true
+	}
+
+	return append(bounds, bound), true
+}
+
+// rangeBounds returns the bounds of a document that only consists of `$gt`, `$gte`, `$lt` and `$lte`.
+func rangeBounds(value interface{}) (bson.D, bool) {
+	bounds, ok := value.(bson.D)
+	if !ok || len(bounds) == 0 {
+		return nil, false
+	}
+
+	for _, bound := range bounds {
+		switch bound.Key {
+		case "$gt", "$gte", "$lt", "$lte":
+		default:
+			return nil, false
+		}
+	}
+
+	return bounds, true
+}
+
+// isLowerBound checks if a range operator is a lower bound.
+func isLowerBound(key string) bool {
+	return key == "$gt" || key == "$gte"
+}
+
+// isExclusiveBound checks if a range operator excludes its value.
+func isExclusiveBound(key string) bool {
+	return key == "$gt" || key == "$lt"
+}
+
+// checkContradictions returns a `ContradictionError` if conditions on a field can't match together.
+func (o optimizer) checkContradictions(conditions []bson.E) error {
+	if o.negated {
+		return nil
+	}
+
+	for i, condition := range conditions {
+		if strings.HasPrefix(condition.Key, "$") {
+			continue
+		}
+
+		scalar := o.scalar != nil && o.scalar(condition.Key)
+		if scalar && isEmptyRange(condition.Value) {
+			return ContradictionError{field: condition.Key}
+		}
+
+		for _, other := range conditions[i+1:] {
+			if other.Key == condition.Key &&
+				(contradicts(condition.Value, other.Value, scalar) || contradicts(other.Value, condition.Value, scalar)) {
+				return ContradictionError{field: condition.Key}
+			}
+		}
+	}
+
+	return nil
+}
+
+// contradicts checks if a condition can't match when the other one does. Conditions
+// that only contradict on single values are checked if the field can't be an array.
+//
+//nolint:cyclop
+func contradicts(value, other interface{}, scalar bool) bool {
+	if negated, ok := operand(other, "$not"); ok && reflect.DeepEqual(value, negated) {
+		return true
+	}
+
+	if exists, ok := operand(value, "$exists"); ok {
+		otherExists, ok := operand(other, "$exists")
+
+		return ok && exists != otherExists
+	}
+
+	if values, ok := listOperand(value, "$in"); ok {
+		excluded, ok := listOperand(other, "$nin")
+
+		return ok && containsAll(excluded, values)
+	}
+
+	if !isPlainValue(value) {
+		return false
+	}
+
+	if unequal, ok := operand(other, "$ne"); ok {
+		return valuesEqual(value, unequal)
+	}
+
+	if excluded, ok := listOperand(other, "$nin"); ok {
+		return containsAll(excluded, bson.A{value})
+	}
+
+	if !scalar {
+		return false
+	}
+
+	if isPlainValue(other) {
+		return !valuesEqual(value, other)
+	}
+
+	return isOutOfRange(value, other)
+}
+
+// isOutOfRange checks if a value violates a bound of a range document.
+func isOutOfRange(value, other interface{}) bool {
+	bounds, ok := rangeBounds(other)
+	if !ok {
+		return false
+	}
+
+	for _, bound := range bounds {
+		cmp, ok := compareValues(value, bound.Value)
+		if !ok {
+			continue
+		}
+
+		if !isLowerBound(bound.Key) {
+			cmp = -cmp
+		}
+
+		if cmp < 0 || (cmp == 0 && isExclusiveBound(bound.Key)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEmptyRange checks if the lower bound of a range document exceeds the upper bound.
+func isEmptyRange(value interface{}) bool {
+	bounds, ok := rangeBounds(value)
+	if !ok {
+		return false
+	}
+
+	for _, lower := range bounds {
+		for _, upper := range bounds {
+			if !isLowerBound(lower.Key) || isLowerBound(upper.Key) {
+				continue
+			}
+
+			cmp, ok := compareValues(lower.Value, upper.Value)
+			if ok && (cmp > 0 || (cmp == 0 && (isExclusiveBound(lower.Key) || isExclusiveBound(upper.Key)))) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// filterList returns the filters of a logical operation.
+func filterList(value interface{}) ([]bson.D, bool) {
+	items, ok := value.(bson.A)
+	if !ok || len(items) == 0 {
+		return nil, false
+	}
+
+	filters := make([]bson.D, 0, len(items))
+
+	for _, item := range items {
+		filter, ok := item.(bson.D)
+		if !ok {
+			return nil, false
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, true
+}
+
+// operand returns the value of a document that consists of a single operator.
+func operand(value interface{}, key string) (interface{}, bool) {
+	document, ok := value.(bson.D)
+	if !ok || len(document) != 1 || document[0].Key != key {
+		return nil, false
+	}
+
+	return document[0].Value, true
+}
+
+// listOperand returns the list of `$in` or `$nin`, that
+// are emitted as single element instead of a document.
+func listOperand(value interface{}, key string) (bson.A, bool) {
+	if element, ok := value.(bson.E); ok {
+		value = bson.D{element}
+	}
+
+	list, ok := operand(value, key)
+	if !ok {
+		return nil, false
+	}
+
+	values, ok := list.(bson.A)
+
+	return values, ok
+}
+
+// isPlainValue checks if a value is compared with `==` and not a document, list or expression.
+func isPlainValue(value interface{}) bool {
+	switch value.(type) {
+	case bson.D, bson.E, bson.A, bson.M, primitive.Regex:
+		return false
+	}
+
+	return true
+}
+
+// containsAll checks if a list contains all given values.
+func containsAll(list, values bson.A) bool {
+	for _, value := range values {
+		found := false
+
+		for _, item := range list {
+			if valuesEqual(item, value) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// valuesEqual checks if two values are equal like MongoDB compares them, e.g. `1` equals `1.0`.
+func valuesEqual(value, other interface{}) bool {
+	if cmp, ok := compareValues(value, other); ok {
+		return cmp == 0
+	}
+
+	return reflect.DeepEqual(value, other)
+}
+
+// compareValues compares two values of the same type, numbers of different types are
+// compared by value. The result is false if the values can't be compared.
+//
+//nolint:cyclop
+func compareValues(value, other interface{}) (int, bool) {
+	switch value := value.(type) {
+	case int32, int64, float64:
+		return compareNumbers(value, other)
+	case string:
+		if other, ok := other.(string); ok {
+			return strings.Compare(value, other), true
+		}
+	case bool:
+		if other, ok := other.(bool); ok {
+			return compareInts(boolToInt(value), boolToInt(other)), true
+		}
+	case primitive.DateTime:
+		if other, ok := other.(primitive.DateTime); ok {
+			return compareInts(int64(value), int64(other)), true
+		}
+	case primitive.ObjectID:
+		if other, ok := other.(primitive.ObjectID); ok {
+			return bytes.Compare(value[:], other[:]), true
+		}
+	}
+
+	return 0, false
+}
+
+// compareNumbers compares two numbers, integers are compared without conversion to floats.
+func compareNumbers(value, other interface{}) (int, bool) {
+	valueInt, valueIsInt := toInt(value)
+	otherInt, otherIsInt := toInt(other)
+
+	if valueIsInt && otherIsInt {
+		return compareInts(valueInt, otherInt), true
+	}
+
+	valueFloat, ok := toFloat(value)
+	if !ok {
+		return 0, false
+	}
+
+	otherFloat, ok := toFloat(other)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case valueFloat < otherFloat:
+		return -1, true
+	case valueFloat > otherFloat:
+		return 1, true
+	case valueFloat == otherFloat:
+		return 0, true
+	}
+
+	// NaN can't be compared
+	return 0, false
+}
+
+// toInt converts integers into an `int64`.
+func toInt(value interface{}) (int64, bool) {
+	switch value := value.(type) {
+	case int32:
+		return int64(value), true
+	case int64:
+		return value, true
+	}
+
+	return 0, false
+}
+
+// toFloat converts numbers into a `float64`.
+func toFloat(value interface{}) (float64, bool) {
+	if value, ok := toInt(value); ok {
+		return float64(value), true
+	}
+
+	number, ok := value.(float64)
+
+	return number, ok
+}
+
+// compareInts compares two integers.
+func compareInts(value, other int64) int {
+	switch {
+	case value < other:
+		return -1
+	case value > other:
+		return 1
+	}
+
+	return 0
+}
+
+// boolToInt converts `false` to 0 and `true` to 1.
+func boolToInt(value bool) int64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,211 @@
+package rsql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+//nolint:funlen
+func TestOptimize(t *testing.T) {
+	t.Parallel()
+
+	optimize := func(t *testing.T, query string) (bson.D, error) {
+		t.Helper()
+
+		filter, err := NewParser(nil).Parse(query)
+		require.NoError(t, err, query)
+
+		return Optimize(filter)
+	}
+
+	t.Run("Flatten_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]bson.D{
+			`(a==1;(b==2;c==3))`: {{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: int64(1)}},
+				bson.D{{Key: "b", Value: int64(2)}},
+				bson.D{{Key: "c", Value: int64(3)}},
+			}}},
+			`((a==1,b==2),(c==3))`: {{Key: "$or", Value: bson.A{
+				bson.D{{Key: "a", Value: int64(1)}},
+				bson.D{{Key: "b", Value: int64(2)}},
+				bson.D{{Key: "c", Value: int64(3)}},
+			}}},
+			`((a==1))`: {{Key: "a", Value: int64(1)}},
+		} {
+			actual, err := optimize(t, query)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, actual, query)
+		}
+	})
+
+	t.Run("MergeRanges_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]bson.D{
+			`a=gt=1;a=lt=5`: {{Key: "a", Value: bson.D{{Key: "$gt", Value: int64(1)}, {Key: "$lt", Value: int64(5)}}}},
+			`a=ge=1;b==2;a=gt=3;a=le=9.5;a=lt=9.5`: {{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: bson.D{{Key: "$gt", Value: int64(3)}, {Key: "$lt", Value: 9.5}}}},
+				bson.D{{Key: "b", Value: int64(2)}},
+			}}},
+			`a=ge=1;a=gt=1`: {{Key: "a", Value: bson.D{{Key: "$gt", Value: int64(1)}}}},
+			`a=gt=1;a=gt=$date(2024-01-01)`: {{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: bson.D{{Key: "$gt", Value: int64(1)}}}},
+				bson.D{{Key: "a", Value: bson.D{{Key: "$gt", Value: primitive.NewDateTimeFromTime(
+					time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				)}}}},
+			}}},
+			`list=em=(a=gt=1;a=lt=2)`: {{Key: "list", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+				{Key: "a", Value: bson.D{{Key: "$gt", Value: int64(1)}, {Key: "$lt", Value: int64(2)}}},
+			}}}}},
+		} {
+			actual, err := optimize(t, query)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, actual, query)
+		}
+	})
+
+	t.Run("CollapseEqualities_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]bson.D{
+			`a==1,a==2,a==3`: {{Key: "a", Value: bson.D{{Key: "$in", Value: bson.A{int64(1), int64(2), int64(3)}}}}},
+			`a==1,b==2,a=in=(1,3)`: {{Key: "$or", Value: bson.A{
+				bson.D{{Key: "a", Value: bson.D{{Key: "$in", Value: bson.A{int64(1), int64(3)}}}}},
+				bson.D{{Key: "b", Value: int64(2)}},
+			}}},
+			`a==1,a==1.0`: {{Key: "a", Value: int64(1)}},
+		} {
+			actual, err := optimize(t, query)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, actual, query)
+		}
+	})
+
+	t.Run("CollapseEqualitiesWireFormat_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]string{
+			`a==1,a==2`:             `{"a":{"$in":[1,2]}}`,
+			`a==1,b==2,a=in=(1,3)`:  `{"$or":[{"a":{"$in":[1,3]}},{"b":2}]}`,
+			`a=out=(1);(b==1,b==2)`: `{"$and":[{"a":{"$nin":[1]}},{"b":{"$in":[1,2]}}]}`,
+		} {
+			actual, err := optimize(t, query)
+			require.NoError(t, err, query)
+
+			json, err := bson.MarshalExtJSON(actual, false, false)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, string(json), query)
+		}
+	})
+
+	t.Run("Duplicates_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]bson.D{
+			`a==1;a==1`: {{Key: "a", Value: int64(1)}},
+			`(a==1;b==2),(a==1;b==2)`: {{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: int64(1)}},
+				bson.D{{Key: "b", Value: int64(2)}},
+			}}},
+		} {
+			actual, err := optimize(t, query)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, actual, query)
+		}
+	})
+
+	t.Run("ArraySafe_Success", func(t *testing.T) {
+		t.Parallel()
+
+		// an array like [1, 2] or [1, 9] matches these filters
+		for _, query := range []string{`a==1;a==2`, `a=gt=5;a=lt=3`, `a==1;a=gt=5`} {
+			_, err := optimize(t, query)
+			require.NoError(t, err, query)
+		}
+	})
+
+	t.Run("ContradictingBranch_Success", func(t *testing.T) {
+		t.Parallel()
+
+		actual, err := optimize(t, `(a==1;a!=1),b==2`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{{Key: "b", Value: int64(2)}}, actual)
+	})
+
+	t.Run("ContradictionInNegation_Success", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := optimize(t, `!(a==1;a!=1)`)
+		require.NoError(t, err)
+	})
+
+	t.Run("Contradiction_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for _, query := range []string{
+			`a==1;a!=1`,
+			`a==1;a=out=(1,2)`,
+			`a=in=(1,2);a=out=(1,2,3)`,
+			`a=exists=true;b==1;a=exists=false`,
+			`a=null=true;a=null=false`,
+			`a=sw="x";!a=sw="x"`,
+			`(a==1;a!=1),(b==1;b!=1)`,
+			`list=em=(a==1;a!=1)`,
+		} {
+			_, err := optimize(t, query)
+			require.IsType(t, ContradictionError{}, err, query)
+		}
+	})
+}
+
+func TestParserOptimization(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Enabled_Success", func(t *testing.T) {
+		t.Parallel()
+
+		actual, err := NewParser(nil).EnableOptimization().Parse(`a=gt=1;a=lt=5`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{{Key: "a", Value: bson.D{{Key: "$gt", Value: int64(1)}, {Key: "$lt", Value: int64(5)}}}}, actual)
+	})
+
+	t.Run("SmartParser_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+		require.NoError(t, err)
+
+		// roles is an array and ["a", "b"] matches both
+		_, err = parser.EnableOptimization().Parse(`roles=="a";roles=="b"`)
+		require.NoError(t, err)
+	})
+
+	t.Run("SmartParser_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+		require.NoError(t, err)
+
+		parser.EnableOptimization()
+
+		for query, field := range map[string]string{
+			`age==1;age==2`:           "age",
+			`age=gt=5;age=lt=3`:       "age",
+			`age=ge=3;age=lt=3`:       "age",
+			`score==1;score=gt=1`:     "score",
+			`name=="x";name=="y"`:     "name",
+			`version==1;version=lt=1`: "version",
+			`created_at=ge=$date(2024-01-02);created_at=lt=$date(2024-01-01)`: "created_at",
+		} {
+			_, err := parser.Parse(query)
+			require.Equal(t, ContradictionError{field: field}, err, query)
+		}
+	})
+}
@@ -2,13 +2,14 @@ package rsql
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
 	"github.com/StevenCyb/goapiutils/parser/tokenizer"
-	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -20,13 +21,25 @@ const (
 	OrCompositeType                      tokenizer.Type = ","
 	ContextStartType                     tokenizer.Type = "("
 	ContextEndType                       tokenizer.Type = ")"
+	NotType                              tokenizer.Type = "!"
 	ValueCompareOperatorType             tokenizer.Type = "VALUE_COMPARE_OPERATOR"
 	QuotedStringValueCompareOperatorType tokenizer.Type = "QUOTED_STRING_VALUE_COMPARE_OPERATOR"
 	NumericValueCompareOperatorType      tokenizer.Type = "NUMERIC_VALUE_COMPARE_OPERATOR"
 	ArrayCompareOperatorType             tokenizer.Type = "ARRAY_COMPARE_OPERATOR"
+	BoolValueCompareOperatorType         tokenizer.Type = "BOOL_VALUE_COMPARE_OPERATOR"
+	CustomCompareOperatorType            tokenizer.Type = "CUSTOM_COMPARE_OPERATOR"
+	SizeCompareOperatorType              tokenizer.Type = "SIZE_COMPARE_OPERATOR"
+	ElemMatchOperatorType                tokenizer.Type = "ELEM_MATCH_OPERATOR"
+	TextOperatorType                     tokenizer.Type = "TEXT_OPERATOR"
+	GeoCompareOperatorType               tokenizer.Type = "GEO_COMPARE_OPERATOR"
 	BoolLiteralType                      tokenizer.Type = "BOOL_LITERAL"
+	NullLiteralType                      tokenizer.Type = "NULL_LITERAL"
 	QuotedStringLiteralType              tokenizer.Type = "QUOTED_STRING_LITERAL"
 	OidLiteralType                       tokenizer.Type = "OID_LITERAL"
+	DateLiteralType                      tokenizer.Type = "DATE_LITERAL"
+	DecimalLiteralType                   tokenizer.Type = "DECIMAL_LITERAL"
+	RelativeDateLiteralType              tokenizer.Type = "RELATIVE_DATE_LITERAL"
+	PlaceholderLiteralType               tokenizer.Type = "PLACEHOLDER_LITERAL"
 	FieldNameType                        tokenizer.Type = "FIELD_NAME"
 	NumberLiteralType                    tokenizer.Type = "NUMERIC_LITERAL"
 
@@ -35,37 +48,161 @@ const (
 	float64Size = 64
 )
 
-// specialEncode is the map for encoding
-// a list of special characters.
-//
-//nolint:gochecknoglobals
-var specialEncode = map[string]string{
-	`,`: "%5C%2C",
-	`;`: "%5C%3B",
-	`=`: "%5C%3D",
-	`"`: "%22",
-	`'`: "%27",
-	`$`: "%24",
-	` `: "%20",
-}
-
 // NewParser creates a new parser.
 func NewParser(policy *tokenizer.Policy) *Parser {
-	return &Parser{
-		policy: policy,
+	parser := &Parser{
+		policy:    policy,
+		emitter:   NewMongoEmitter(),
+		operators: map[Operator]customOperator{},
+		clock:     time.Now,
+	}
+	parser.compile()
+
+	return parser
+}
+
+// NewSmartParser creates a new parser that rejects fields that are not part of
+// given type and coerces literals to the types of the fields (using `bson` tags).
+func NewSmartParser(reference reflect.Type) (*Parser, error) {
+	schema, err := newSchema(reference)
+	if err != nil {
+		return nil, err
 	}
+
+	parser := NewParser(nil)
+	parser.schema = schema
+
+	return parser, nil
 }
 
-// Parser provides the logic to parse
-// rsql statements.
+// Parser provides the logic to parse rsql statements. The grammar is compiled
+// once when the parser is configured and the state of a parse is kept per call,
+// so a configured parser can be used by multiple goroutines.
 type Parser struct {
-	tokenizer *tokenizer.Tokenizer
-	lookahead *tokenizer.Token
-	policy    *tokenizer.Policy
+	specs            []*tokenizer.Spec
+	templateSpecs    []*tokenizer.Spec
+	unquotedNumber   *regexp.Regexp
+	policy           *tokenizer.Policy
+	fieldPolicies    FieldPolicies
+	emitter          *MongoEmitter
+	operators        map[Operator]customOperator
+	clock            func() time.Time
+	schema           *schema
+	mapping          *fieldmap.Mapping
+	limits           Limits
+	numeric          NumericOptions
+	dialect          Dialect
+	unsafeFieldNames bool
+	optimize         bool
+}
+
+// SetLimits sets limits that restrict the complexity of queries.
+func (p *Parser) SetLimits(limits Limits) *Parser {
+	p.limits = limits
+
+	return p
+}
+
+// SetNumericOptions sets the syntax and types of numeric literals.
+func (p *Parser) SetNumericOptions(options NumericOptions) *Parser {
+	p.numeric = options
+	p.emitter.numericVariants = options.MixedTypes
+	p.compile()
+
+	return p
+}
+
+// SetFieldPolicies sets policies that restrict the operators and values of fields.
+// Violations are rejected with an `errs.PolicyViolationError` that names the field,
+// operator and reason. Policies are checked with the field names of the query.
+func (p *Parser) SetFieldPolicies(policies FieldPolicies) *Parser {
+	p.fieldPolicies = policies
+
+	return p
+}
+
+// SetTextSearchOptions sets the options of the `$text` clauses of text searches.
+func (p *Parser) SetTextSearchOptions(options TextSearchOptions) *Parser {
+	p.emitter.textSearch = options
+
+	return p
+}
+
+// SetDialect sets the syntax the parser accepts, the default is `NativeDialect`.
+func (p *Parser) SetDialect(dialect Dialect) *Parser {
+	p.dialect = dialect
+	p.compile()
+
+	return p
+}
+
+// SetClock sets the clock that relative dates like `$now(-7d)` are resolved against.
+func (p *Parser) SetClock(clock func() time.Time) *Parser {
+	p.clock = clock
+
+	return p
+}
+
+// SetFieldMapping sets a mapping from the field names used in queries to storage paths.
+// Fields without mapping are rejected, the policy is checked with the unmapped names.
+func (p *Parser) SetFieldMapping(mapping *fieldmap.Mapping) *Parser {
+	p.mapping = mapping
+
+	return p
+}
+
+// AllowUnsafeFieldNames disables the field name validation that rejects
+// names with `$` prefixed or empty segments and control characters.
+// Only use this for trusted queries.
+func (p *Parser) AllowUnsafeFieldNames() *Parser {
+	p.unsafeFieldNames = true
+
+	return p
+}
+
+// EnableOptimization optimizes the filters returned by `Parse` (see `Optimize`).
+// A smart parser knows which fields can't be arrays and detects all contradictions on them.
+func (p *Parser) EnableOptimization() *Parser {
+	p.optimize = true
+
+	return p
+}
+
+// RegisterOperator register a custom comparison operator (format `=name=`).
+// The handler creates the filter element from the field name and the arguments.
+func (p *Parser) RegisterOperator(operator string, argKind ArgKind, handler OperatorHandler) error {
+	if handler == nil {
+		return ErrNilHandler
+	}
+
+	if !operatorFormat.MatchString(operator) {
+		return ErrInvalidOperator
+	}
+
+	if _, exists := p.operators[Operator(operator)]; exists || isBuiltinOperator(Operator(operator)) {
+		return ErrDuplicateOperator
+	}
+
+	p.operators[Operator(operator)] = customOperator{argKind: argKind, handler: handler}
+	p.emitter.RegisterOperator(Operator(operator), handler)
+	p.compile()
+
+	return nil
+}
+
+// state is the state of a single parse.
+type state struct {
+	*Parser
+	tokenizer     *tokenizer.Tokenizer
+	lookahead     *tokenizer.Token
+	storagePrefix string
+	prefix        string
+	depth         int
+	comparisons   int
 }
 
 // eat return a token with expected type.
-func (p *Parser) eat(tokenType tokenizer.Type) (*tokenizer.Token, error) {
+func (p *state) eat(tokenType tokenizer.Type) (*tokenizer.Token, error) {
 	token := p.lookahead
 
 	if token == nil {
@@ -81,128 +218,325 @@ func (p *Parser) eat(tokenType tokenizer.Type) (*tokenizer.Token, error) {
 	}
 
 	var err error
-	p.lookahead, err = p.tokenizer.GetNextToken()
+	p.lookahead, err = p.next()
+
+	return token, err
+}
+
+// next return the next token, unescapes field names and checks them against
+// the policy. Field names within `=em=` are checked with the path of the array.
+func (p *state) next() (*tokenizer.Token, error) {
+	token, err := p.tokenizer.GetNextToken()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
 
-	return token, err //nolint:wrapcheck
+	if token != nil && p.dialect == CompatDialect {
+		return p.compatToken(token), nil
+	}
+
+	if token == nil || token.Type != FieldNameType {
+		return token, nil
+	}
+
+	token.Value = tokenizer.Unescape(token.Value)
+
+	if p.policy != nil && !p.policy.Allow(p.prefix+token.Value) {
+		return nil, errs.NewErrPolicyViolation(p.prefix + token.Value)
+	}
+
+	return token, nil
 }
 
-// Parse a given query.
+// compatToken normalizes operator aliases and classifies unquoted literals
+// of the compatibility dialect. The policy is checked by fieldName()
+// since unquoted literals are only known to be field names by position.
+func (p *state) compatToken(token *tokenizer.Token) *tokenizer.Token {
+	switch token.Type { //nolint:exhaustive
+	case ValueCompareOperatorType, NumericValueCompareOperatorType, ArrayCompareOperatorType:
+		if operator, exists := operatorAliases[token.Value]; exists {
+			token.Value = string(operator)
+		}
+	case UnquotedLiteralType:
+		classifyUnquoted(token, p.unquotedNumber)
+	}
+
+	return token
+}
+
+// Parse a given query into a MongoDB filter.
 func (p *Parser) Parse(query string) (bson.D, error) {
+	node, err := p.ParseAST(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.emit(node)
+}
+
+// emit converts an abstract syntax tree into a MongoDB filter and optimizes it if enabled.
+func (p *Parser) emit(node Node) (bson.D, error) {
+	filter, err := p.emitter.Emit(node)
+	if err != nil || !p.optimize {
+		return filter, err
+	}
+
+	optimizer := optimizer{}
+	if p.schema != nil {
+		optimizer.scalar = p.schema.isScalar
+	}
+
+	return optimizer.filter(filter)
+}
+
+// ParseAST parses a given query into an abstract syntax tree.
+// An empty query results in a nil node.
+func (p *Parser) ParseAST(query string) (Node, error) {
+	node, err := p.parse(query, p.specs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.validate(node); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// parse parses a query with given specs into an abstract syntax tree.
+func (p *Parser) parse(query string, specs []*tokenizer.Spec) (Node, error) {
 	var err error
 
 	if query == "" {
-		return bson.D{}, nil
-	}
-
-	for dec, enc := range specialEncode {
-		query = strings.ReplaceAll(query, enc, dec)
-	}
-
-	p.tokenizer = tokenizer.NewTokenizer(
-		query,
-		SkipType, FieldNameType,
-		[]*tokenizer.Spec{
-			tokenizer.NewSpec(`^\s+`, SkipType),
-			tokenizer.NewSpec(`^\(`, ContextStartType),
-			tokenizer.NewSpec(`^\)`, ContextEndType),
-			tokenizer.NewSpec(`^;`, AndCompositeType),
-			tokenizer.NewSpec(`^,`, OrCompositeType),
-			tokenizer.NewSpec(`^(==|!=)`, ValueCompareOperatorType),
-			tokenizer.NewSpec(`^(=sw=|=ew=)`, QuotedStringValueCompareOperatorType),
-			tokenizer.NewSpec(`^(=gt=|=ge=|=lt=|=le=)`, NumericValueCompareOperatorType),
-			tokenizer.NewSpec(`^(=in=|=out=)`, ArrayCompareOperatorType),
-			tokenizer.NewSpec(`^\$oid\([0-9a-fA-F]+\)`, OidLiteralType),
-			tokenizer.NewSpec(`(?i)^(true|false)`, BoolLiteralType),
-			tokenizer.NewSpec(`^(-|\+)?\d+(\.\d+)?`, NumberLiteralType),
-			tokenizer.NewSpec(`^("[^"]*"|'[^']*')`, QuotedStringLiteralType),
-			tokenizer.NewSpec(`^[^!=]*`, FieldNameType),
-		},
-		p.policy,
-	)
+		return nil, nil //nolint:nilnil
+	}
+
+	// the length is checked before tokenizing, so the error points to the start of the query
+	if err := check(MaxQueryLengthLimit, p.limits.MaxQueryLength, len(query), 0); err != nil {
+		return nil, err
+	}
 
-	p.lookahead, err = p.tokenizer.GetNextToken()
+	query, err = tokenizer.DecodeQuery(query)
 	if err != nil {
 		return nil, err //nolint:wrapcheck
 	}
 
-	return p.expression()
+	// The policy is checked by next() since
+	// field names of `=em=` are relative to the array.
+	state := &state{
+		Parser: p,
+		tokenizer: tokenizer.NewTokenizer(
+			query,
+			SkipType, FieldNameType,
+			specs,
+			nil,
+		),
+	}
+
+	state.lookahead, err = state.next()
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := state.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	return node, checkPlacement(node)
+}
+
+// checkPlacement checks that operators MongoDB only accepts once and
+// within AND (text searches and `=near=`) are placed accordingly.
+func checkPlacement(node Node) error {
+	if err := checkTextSearch(node); err != nil {
+		return err
+	}
+
+	return checkNear(node)
+}
+
+// validate checks an abstract syntax tree against the field policies
+// and the schema. The schema coerces the literals to the types of the fields.
+func (p *Parser) validate(node Node) error {
+	if node == nil {
+		return nil
+	}
+
+	// Field policies are checked before the schema coerces the literals.
+	if p.fieldPolicies != nil {
+		if err := p.fieldPolicies.check(node, ""); err != nil {
+			return err
+		}
+	}
+
+	if p.schema != nil {
+		return p.schema.apply(node, "")
+	}
+
+	return nil
+}
+
+// compile compiles the expressions of the grammar that depend on the configuration.
+func (p *Parser) compile() {
+	p.specs = p.compileSpecs(false)
+	p.templateSpecs = p.compileSpecs(true)
+	p.unquotedNumber = regexp.MustCompile(`^` + numberExpression(p.numeric) + `$`)
+}
+
+// compileSpecs returns the tokenizer specs of the dialect including registered operators
+// and the placeholders of templates if enabled.
+func (p *Parser) compileSpecs(placeholders bool) []*tokenizer.Spec {
+	specs := []*tokenizer.Spec{}
+	if p.dialect == CompatDialect {
+		specs = compatSpecs()
+	}
+
+	specs = append(specs,
+		tokenizer.NewSpec(`^\s+`, SkipType),
+		tokenizer.NewSpec(`^\(`, ContextStartType),
+		tokenizer.NewSpec(`^\)`, ContextEndType),
+		tokenizer.NewSpec(`^;`, AndCompositeType),
+		tokenizer.NewSpec(`^,`, OrCompositeType),
+		tokenizer.NewSpec(`^(==|!=)`, ValueCompareOperatorType),
+		tokenizer.NewSpec(`^!`, NotType),
+		tokenizer.NewSpec(`^(=sw=|=ew=|=like=|=contains=|=isw=|=iew=|=ieq=)`, QuotedStringValueCompareOperatorType),
+		tokenizer.NewSpec(`^(=gt=|=ge=|=lt=|=le=)`, NumericValueCompareOperatorType),
+		tokenizer.NewSpec(`^(=in=|=out=|=all=)`, ArrayCompareOperatorType),
+		tokenizer.NewSpec(`^(=exists=|=null=)`, BoolValueCompareOperatorType),
+		tokenizer.NewSpec(`^=size=`, SizeCompareOperatorType),
+		tokenizer.NewSpec(`^=em=`, ElemMatchOperatorType),
+		tokenizer.NewSpec(`^=text=`, TextOperatorType),
+		tokenizer.NewSpec(`^(=near=|=within=|=box=)`, GeoCompareOperatorType),
+	)
+
+	if len(p.operators) > 0 {
+		specs = append(specs, tokenizer.NewSpec(operatorExpression(p.operators), CustomCompareOperatorType))
+	}
+
+	specs = append(specs,
+		tokenizer.NewSpec(`^\$oid\([0-9a-fA-F]+\)`, OidLiteralType),
+		tokenizer.NewSpec(`^\$date\([^)]*\)`, DateLiteralType),
+		tokenizer.NewSpec(`^\$dec\([^)]*\)`, DecimalLiteralType),
+		tokenizer.NewSpec(`^\$now\([^)]*\)`, RelativeDateLiteralType),
+	)
+
+	if placeholders {
+		specs = append(specs, tokenizer.NewSpec(`^:[a-zA-Z_][a-zA-Z0-9_]*`, PlaceholderLiteralType))
+	}
+
+	quoted := tokenizer.NewSpec(`^("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')`, QuotedStringLiteralType)
+
+	if p.dialect == CompatDialect {
+		return append(specs, quoted, unquotedSpec())
+	}
+
+	return append(specs,
+		tokenizer.NewSpec(`(?i)^(true|false)`, BoolLiteralType),
+		tokenizer.NewSpec(`(?i)^null\b`, NullLiteralType),
+		tokenizer.NewSpec(`^`+numberExpression(p.numeric), NumberLiteralType),
+		quoted,
+		tokenizer.NewSpec(`^(?:[^!=\\]|\\.)*`, FieldNameType),
+	)
 }
 
 /*
  * <expression>
- *   : <context>
- *   | <context> <composite_operator> <expression>
- *   | <comparison>
- *   | <comparison> <composite_operator> <expression>
+ *   : <term>
+ *   | <term> <composite_operator> <expression>
  * .
  */
-func (p *Parser) expression() ([]bson.E, error) { //nolint:funlen
-	var (
-		left           bson.E
-		sortStatements = []bson.E{}
-	)
+func (p *state) expression() (Node, error) {
+	if p.dialect == CompatDialect {
+		return p.orExpression()
+	}
 
-	if p.lookahead == nil {
-		return nil, errs.NewErrUnexpectedInputEnd(FieldNameType.String())
+	left, err := p.term()
+	if err != nil {
+		return nil, err
 	}
 
-	if p.lookahead.Type == ContextStartType {
-		tmp, err := p.context()
-		if err != nil {
-			return nil, err
-		}
+	if p.lookahead == nil || p.lookahead.Type == ContextEndType {
+		return left, nil
+	}
 
-		left = tmp[0]
-	} else {
-		tmp, err := p.comparison()
-		if err != nil {
-			return nil, err
-		}
-		left = *tmp
+	logicalOperation, err := p.compositeOperation()
+	if err != nil {
+		return nil, err
 	}
 
-	//nolint:nestif
-	if p.lookahead != nil && p.lookahead.Type != ContextEndType {
-		logicalOperation, err := p.compositeOperation()
-		if err != nil {
-			return nil, err
-		}
+	right, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
 
-		right, err := p.expression()
-		if err != nil {
-			return nil, err
-		}
+	if logicalOperation.Type == AndCompositeType {
+		if and, ok := right.(*AndNode); ok {
+			and.Children = append([]Node{left}, and.Children...)
+			and.Position = left.GetPosition()
 
-		if logicalOperation.Type == AndCompositeType {
-			if right[0].Key == "$and" {
-				newA, ok := right[0].Value.(bson.A)
-				if !ok {
-					return nil, errs.NewErrUnexpectedInput(right[0].Value)
-				}
-
-				newA = append(bson.A{bson.D{left}}, newA...)
-				left = bson.E{Key: "$and", Value: newA}
-			} else {
-				left = bson.E{Key: "$and", Value: bson.A{bson.D{left}, bson.D{right[0]}}}
-			}
-		} else {
-			if right[0].Key == "$or" {
-				newA, ok := right[0].Value.(bson.A)
-				if !ok {
-					return nil, errs.NewErrUnexpectedInput(right[0].Value)
-				}
-
-				newA = append(bson.A{bson.D{left}}, newA...)
-				left = bson.E{Key: "$or", Value: newA}
-			} else {
-				left = bson.E{Key: "$or", Value: bson.A{bson.D{left}, bson.D{right[0]}}}
-			}
+			return and, nil
 		}
+
+		return &AndNode{Children: []Node{left, right}, Position: left.GetPosition()}, nil
+	}
+
+	if or, ok := right.(*OrNode); ok {
+		or.Children = append([]Node{left}, or.Children...)
+		or.Position = left.GetPosition()
+
+		return or, nil
 	}
 
-	sortStatements = append(sortStatements, left)
+	return &OrNode{Children: []Node{left, right}, Position: left.GetPosition()}, nil
+}
+
+/*
+ * <term>
+ *   : <negation>
+ *   | <context>
+ *   | <comparison>
+ * .
+ */
+func (p *state) term() (Node, error) {
+	if p.lookahead == nil {
+		return nil, errs.NewErrUnexpectedInputEnd(FieldNameType.String())
+	}
+
+	switch p.lookahead.Type {
+	case NotType:
+		return p.negation()
+	case ContextStartType:
+		return p.context()
+	default:
+		return p.comparison()
+	}
+}
+
+/*
+ * <negation>
+ *   : "!" <term>
+ * .
+ */
+func (p *state) negation() (*NotNode, error) {
+	defer p.leave()
+
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+
+	not, err := p.eat(NotType)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := p.term()
+	if err != nil {
+		return nil, err
+	}
 
-	return sortStatements, nil
+	return &NotNode{Child: child, Position: not.Position}, nil
 }
 
 /*
@@ -210,8 +544,14 @@ func (p *Parser) expression() ([]bson.E, error) { //nolint:funlen
  *   : "(" <expression> ")"
  * .
  */
-func (p *Parser) context() ([]bson.E, error) {
-	_, err := p.eat(ContextStartType)
+func (p *state) context() (*GroupNode, error) {
+	defer p.leave()
+
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+
+	start, err := p.eat(ContextStartType)
 	if err != nil {
 		return nil, err
 	}
@@ -226,7 +566,24 @@ func (p *Parser) context() ([]bson.E, error) {
 		return nil, err
 	}
 
-	return context, nil
+	return &GroupNode{Child: context, Position: start.Position}, nil
+}
+
+// nest increases the depth of nesting and checks the limit.
+func (p *state) nest() error {
+	p.depth++
+
+	position := p.tokenizer.GetCursorPosition()
+	if p.lookahead != nil {
+		position = p.lookahead.Position
+	}
+
+	return check(MaxDepthLimit, p.limits.MaxDepth, p.depth, position)
+}
+
+// leave decreases the depth of nesting.
+func (p *state) leave() {
+	p.depth--
 }
 
 /*
@@ -235,7 +592,7 @@ func (p *Parser) context() ([]bson.E, error) {
  *   | ","
  * .
  */
-func (p *Parser) compositeOperation() (*tokenizer.Token, error) {
+func (p *state) compositeOperation() (*tokenizer.Token, error) {
 	if p.lookahead.Type == AndCompositeType {
 		return p.eat(AndCompositeType)
 	} else if p.lookahead.Type == OrCompositeType {
@@ -251,73 +608,189 @@ func (p *Parser) compositeOperation() (*tokenizer.Token, error) {
 /*
  * <array_comparison>
  *   | <plural_operator> "(" <literal_list> ")"
+ *   | <plural_operator> <placeholder>
  * .
  */
-func (p *Parser) arrayComparison(key string) (*bson.E, error) {
+func (p *state) arrayComparison(node *ComparisonNode) error {
 	operator, err := p.eat(ArrayCompareOperatorType)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	_, err = p.eat(ContextStartType)
+	node.Operator = Operator(operator.Value)
+
+	if p.atPlaceholder() {
+		return p.placeholderArgument(node)
+	}
+
+	start, err := p.eat(ContextStartType)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	literalList, err := p.literalList()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = p.eat(ContextEndType)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	switch operator.Value {
-	case "=in=":
-		return &bson.E{Key: key, Value: bson.E{Key: "$in", Value: literalList}}, nil
-	case "=out=":
-		return &bson.E{Key: key, Value: bson.E{Key: "$nin", Value: literalList}}, nil
-	default:
-		return nil, errs.NewErrUnexpectedTokenType(
-			p.tokenizer.GetCursorPosition()-len(operator.Value),
-			operator.Type.String(),
-			ArrayCompareOperatorType.String())
-	}
+	node.Argument = Literal{Value: literalList, Kind: ListLiteralKind, Position: start.Position}
+
+	return nil
 }
 
 /*
  * <numeric_value_comparison>
- *   | <singular_string_operator> <quoted_string_literal>
+ *   | <numeric_operator> <numeric_literal>
+ *   | <numeric_operator> <date_literal>
+ *   | <numeric_operator> <quoted_string_literal>   (with schema or compatibility dialect)
  * .
  */
-func (p *Parser) numericValueComparison(key string) (*bson.E, error) {
+func (p *state) numericValueComparison(node *ComparisonNode) error {
 	operator, err := p.eat(NumericValueCompareOperatorType)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	var literal *Literal
+
+	switch {
+	case p.lookahead != nil && (p.lookahead.Type == DateLiteralType || p.lookahead.Type == RelativeDateLiteralType):
+		literal, err = p.dateLiteral()
+	case p.acceptsStringRange() && p.lookahead != nil &&
+		(p.lookahead.Type == QuotedStringLiteralType || p.lookahead.Type == UnquotedLiteralType):
+		literal, err = p.stringLiteral()
+	default:
+		literal, err = p.numericLiteral()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	node.Operator = Operator(operator.Value)
+	node.Argument = *literal
+
+	return nil
+}
+
+// acceptsStringRange checks if range operators accept strings. The schema coerces them
+// to dates or ObjectIDs and the compatibility dialect compares them lexicographically
+// like other RSQL libraries. Otherwise they are rejected to avoid lexicographic
+// comparisons by accident.
+func (p *Parser) acceptsStringRange() bool {
+	return p.schema != nil || p.dialect == CompatDialect
+}
+
+/*
+ * <size_comparison>
+ *   | "=size=" <numeric_literal>
+ * .
+ */
+func (p *state) sizeComparison(node *ComparisonNode) error {
+	operator, err := p.eat(SizeCompareOperatorType)
+	if err != nil {
+		return err
 	}
 
 	literal, err := p.numericLiteral()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	switch operator.Value {
-	case "=gt=":
-		return &bson.E{Key: key, Value: bson.D{bson.E{Key: "$gt", Value: literal}}}, nil
-	case "=ge=":
-		return &bson.E{Key: key, Value: bson.D{bson.E{Key: "$gte", Value: literal}}}, nil
-	case "=lt=":
-		return &bson.E{Key: key, Value: bson.D{bson.E{Key: "$lt", Value: literal}}}, nil
-	case "=le=":
-		return &bson.E{Key: key, Value: bson.D{bson.E{Key: "$lte", Value: literal}}}, nil
+	switch size := literal.Value.(type) {
+	case string:
+		if literal.Kind != PlaceholderLiteralKind {
+			return errs.NewErrUnexpectedToken(literal.Position, size)
+		}
+	case int32:
+		if size < 0 {
+			return errs.NewErrUnexpectedToken(literal.Position, fmt.Sprint(literal.Value))
+		}
+	case int64:
+		if size < 0 {
+			return errs.NewErrUnexpectedToken(literal.Position, fmt.Sprint(literal.Value))
+		}
 	default:
-		return nil, errs.NewErrUnexpectedTokenType(
-			p.tokenizer.GetCursorPosition()-len(operator.Value),
-			operator.Type.String(),
-			ValueCompareOperatorType.String())
+		return errs.NewErrUnexpectedToken(literal.Position, fmt.Sprint(literal.Value))
+	}
+
+	node.Operator = Operator(operator.Value)
+	node.Argument = *literal
+
+	return nil
+}
+
+/*
+ * <elem_match>
+ *   | "=em=" <context>
+ * .
+ */
+func (p *state) elemMatch(field *tokenizer.Token) (*ElemMatchNode, error) {
+	_, err := p.eat(ElemMatchOperatorType)
+	if err != nil {
+		return nil, err
+	}
+
+	path, alias, err := p.mapField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, storagePrefix := p.prefix, p.storagePrefix
+	p.prefix, p.storagePrefix = prefix+field.Value+".", storagePrefix+path+"."
+
+	defer func() { p.prefix, p.storagePrefix = prefix, storagePrefix }()
+
+	context, err := p.context()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ElemMatchNode{Field: path, Alias: alias, Child: context.Child, Position: field.Position}, nil
+}
+
+// mapField returns the storage path of a field (relative to the array of an `=em=`)
+// and the name as used in the query if a field mapping is set.
+func (p *state) mapField(field *tokenizer.Token) (string, string, error) {
+	if p.mapping == nil {
+		return field.Value, "", nil
+	}
+
+	name := p.prefix + field.Value
+
+	path, exists := p.mapping.Map(name)
+	if !exists || !strings.HasPrefix(path, p.storagePrefix) {
+		return "", "", errs.NewErrUnmappedField(field.Position, name)
+	}
+
+	return strings.TrimPrefix(path, p.storagePrefix), name, nil
+}
+
+/*
+ * <bool_value_comparison>
+ *   | <bool_operator> <bool_literal>
+ * .
+ */
+func (p *state) boolValueComparison(node *ComparisonNode) error {
+	operator, err := p.eat(BoolValueCompareOperatorType)
+	if err != nil {
+		return err
 	}
+
+	literal, err := p.boolLiteral()
+	if err != nil {
+		return err
+	}
+
+	node.Operator = Operator(operator.Value)
+	node.Argument = *literal
+
+	return nil
 }
 
 /*
@@ -325,127 +798,224 @@ func (p *Parser) numericValueComparison(key string) (*bson.E, error) {
  *   | <singular_string_operator> <quoted_string_literal>
  * .
  */
-func (p *Parser) quotedStringComparison(key string) (*bson.E, error) {
+func (p *state) quotedStringComparison(node *ComparisonNode) error {
 	operator, err := p.eat(QuotedStringValueCompareOperatorType)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	literal, err := p.stringLiteral()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	switch operator.Value {
-	case "=sw=":
-		wildcard, err := regexp.Compile("^" + fmt.Sprintf("%v", literal))
-
-		return &bson.E{Key: key, Value: *wildcard}, errors.Wrap(err, "failed to create wildcard expression")
-	case "=ew=":
-		wildcard, err := regexp.Compile(fmt.Sprintf("%v", literal) + "$")
+	// the length of bound values is checked by the template
+	value, _ := literal.Value.(string)
+	if literal.Kind == PlaceholderLiteralKind {
+		value = ""
+	}
 
-		return &bson.E{Key: key, Value: *wildcard}, errors.Wrap(err, "failed to create wildcard expression")
-	default:
-		return nil, errs.NewErrUnexpectedTokenType(
-			p.tokenizer.GetCursorPosition()-len(operator.Value),
-			operator.Type.String(),
-			ValueCompareOperatorType.String())
+	if err := check(MaxRegexLengthLimit, p.limits.MaxRegexLength, len(value), literal.Position); err != nil {
+		return err
 	}
+
+	node.Operator = Operator(operator.Value)
+	node.Argument = *literal
+
+	return nil
 }
 
 /*
  * <literal_comparison>
  *   : <singular_operator> <literal>
+ *   | <singular_operator> "(" <literal_list> ")"
  * .
  */
-func (p *Parser) literalComparison(key string) (*bson.E, error) {
+func (p *state) literalComparison(node *ComparisonNode) error {
 	operator, err := p.eat(ValueCompareOperatorType)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var literal interface{}
-	//nolint:nestif
-	if p.lookahead.Type == ContextStartType {
-		_, err = p.eat(ContextStartType)
-		if err != nil {
-			return nil, err
-		}
+	node.Operator = Operator(operator.Value)
 
-		literal, err = p.literalList()
+	if p.lookahead == nil || p.lookahead.Type != ContextStartType {
+		literal, err := p.literal()
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		_, err = p.eat(ContextEndType)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		literal, err = p.literal()
+		node.Argument = *literal
+
+		return nil
+	}
+
+	start, err := p.eat(ContextStartType)
+	if err != nil {
+		return err
+	}
+
+	literalList, err := p.literalList()
+	if err != nil {
+		return err
+	}
+
+	_, err = p.eat(ContextEndType)
+	if err != nil {
+		return err
+	}
+
+	node.Argument = Literal{Value: literalList, Kind: ListLiteralKind, Position: start.Position}
+
+	return nil
+}
+
+/*
+ * <custom_comparison>
+ *   : <custom_operator> <literal>
+ *   | <custom_operator> "(" <literal_list> ")"
+ *   | <custom_operator> <placeholder>
+ * .
+ */
+func (p *state) customComparison(node *ComparisonNode) error {
+	operator, err := p.eat(CustomCompareOperatorType)
+	if err != nil {
+		return err
+	}
+
+	node.Operator = Operator(operator.Value)
+
+	if p.operators[node.Operator].argKind == LiteralArg {
+		literal, err := p.literal()
 		if err != nil {
-			return nil, err
+			return err
 		}
+
+		node.Argument = *literal
+
+		return nil
 	}
 
-	switch operator.Value {
-	case "==":
-		return &bson.E{Key: key, Value: literal}, nil
-	case "!=":
-		return &bson.E{Key: key, Value: bson.D{bson.E{Key: "$ne", Value: literal}}}, nil
-	default:
-		return nil, errs.NewErrUnexpectedTokenType(
-			p.tokenizer.GetCursorPosition()-len(operator.Value),
-			operator.Type.String(),
-			ValueCompareOperatorType.String())
+	if p.atPlaceholder() {
+		return p.placeholderArgument(node)
 	}
+
+	start, err := p.eat(ContextStartType)
+	if err != nil {
+		return err
+	}
+
+	literalList, err := p.literalList()
+	if err != nil {
+		return err
+	}
+
+	_, err = p.eat(ContextEndType)
+	if err != nil {
+		return err
+	}
+
+	node.Argument = Literal{Value: literalList, Kind: ListLiteralKind, Position: start.Position}
+
+	return nil
 }
 
 /*
  * <comparison>
- *   : TEXT <singular_operator> <literal>
+ *   : TEXT <literal_comparison>
  *   | TEXT <quoted_string_comparison>
  *   | TEXT <numeric_value_comparison>
  *   | TEXT <array_comparison>
+ *   | TEXT <bool_value_comparison>
+ *   | TEXT <custom_comparison>
+ *   | TEXT <size_comparison>
+ *   | TEXT <geo_comparison>
+ *   | TEXT <elem_match>
+ *   | TEXT <text_search>
  * .
  */
-func (p *Parser) comparison() (*bson.E, error) {
-	keyToken, err := p.eat(FieldNameType)
+func (p *state) comparison() (Node, error) {
+	keyToken, err := p.fieldName()
 	if err != nil {
 		return nil, err
 	}
 
+	p.comparisons++
+	if err := check(MaxComparisonsLimit, p.limits.MaxComparisons, p.comparisons, keyToken.Position); err != nil {
+		return nil, err
+	}
+
+	if !p.unsafeFieldNames {
+		if err := tokenizer.ValidateFieldName(keyToken.Value, keyToken.Position); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
 	if p.lookahead == nil {
 		return nil, errs.NewErrUnexpectedInputEnd(FieldNameType.String())
 	}
 
-	key := keyToken.Value
+	if p.lookahead.Type == ElemMatchOperatorType {
+		return p.elemMatch(keyToken)
+	}
+
+	if p.lookahead.Type == TextOperatorType {
+		return p.textSearch(keyToken)
+	}
+
+	path, alias, err := p.mapField(keyToken)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &ComparisonNode{Field: path, Alias: alias, Position: keyToken.Position}
 
 	switch p.lookahead.Type {
 	case ValueCompareOperatorType:
-		return p.literalComparison(key)
+		err = p.literalComparison(node)
 	case QuotedStringValueCompareOperatorType:
-		return p.quotedStringComparison(key)
+		err = p.quotedStringComparison(node)
 	case NumericValueCompareOperatorType:
-		return p.numericValueComparison(key)
+		err = p.numericValueComparison(node)
 	case ArrayCompareOperatorType:
-		return p.arrayComparison(key)
+		err = p.arrayComparison(node)
+	case BoolValueCompareOperatorType:
+		err = p.boolValueComparison(node)
+	case CustomCompareOperatorType:
+		err = p.customComparison(node)
+	case SizeCompareOperatorType:
+		err = p.sizeComparison(node)
+	case GeoCompareOperatorType:
+		err = p.geoComparison(node)
+	default:
+		return nil, errs.NewErrUnexpectedToken(
+			p.tokenizer.GetCursorPosition()-len(p.lookahead.Value),
+			p.lookahead.Value)
 	}
 
-	return nil, errs.NewErrUnexpectedToken(
-		p.tokenizer.GetCursorPosition()-len(p.lookahead.Value),
-		p.lookahead.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
 }
 
 /*
  * <literal>
  * : <oid_literal>
  * : <bool_literal>
+ * | <null_literal>
  * | <quoted_string_literal>
  * | <numeric_literal>
+ * | <date_literal>
+ * | <placeholder>
  * .
  */
-func (p *Parser) literal() (interface{}, error) {
+func (p *state) literal() (*Literal, error) {
+	if p.lookahead == nil {
+		return nil, errs.NewErrUnexpectedInputEnd("LITERAL")
+	}
+
 	switch p.lookahead.Type {
 	case OidLiteralType:
 		token, err := p.eat(OidLiteralType)
@@ -461,18 +1031,24 @@ func (p *Parser) literal() (interface{}, error) {
 			return nil, fmt.Errorf("could not parse $oid '%s': %w", oidHex, err)
 		}
 
-		return oid, nil
+		return &Literal{Value: oid, Kind: OidLiteralKind, Position: token.Position}, nil
 	case BoolLiteralType:
-		token, err := p.eat(BoolLiteralType)
+		return p.boolLiteral()
+	case NullLiteralType:
+		token, err := p.eat(NullLiteralType)
 		if err != nil {
 			return nil, err
 		}
 
-		return strings.ToLower(token.Value) == "true", nil
-	case QuotedStringLiteralType:
+		return &Literal{Value: nil, Kind: NullLiteralKind, Position: token.Position}, nil
+	case QuotedStringLiteralType, UnquotedLiteralType:
 		return p.stringLiteral()
-	case NumberLiteralType:
+	case NumberLiteralType, DecimalLiteralType:
 		return p.numericLiteral()
+	case DateLiteralType, RelativeDateLiteralType:
+		return p.dateLiteral()
+	case PlaceholderLiteralType:
+		return p.placeholder()
 	}
 
 	return nil, errs.NewErrUnexpectedTokenType(
@@ -481,65 +1057,187 @@ func (p *Parser) literal() (interface{}, error) {
 		"LITERAL")
 }
 
+/*
+ * <bool_literal>
+ * : "true"
+ * | "false"
+ * .
+ */
+func (p *state) boolLiteral() (*Literal, error) {
+	if p.atPlaceholder() {
+		return p.placeholder()
+	}
+
+	token, err := p.eat(BoolLiteralType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Literal{
+		Value:    strings.ToLower(token.Value) == "true",
+		Kind:     BoolLiteralKind,
+		Position: token.Position,
+	}, nil
+}
+
 /*
  * <quoted_string_literal>
  * : "'" <TEXT> "'"
  * | """ <TEXT> """
  * .
  */
-func (p *Parser) stringLiteral() (interface{}, error) {
+func (p *state) stringLiteral() (*Literal, error) {
+	if p.atPlaceholder() {
+		return p.placeholder()
+	}
+
+	if p.dialect == CompatDialect && p.lookahead != nil && p.lookahead.Type != QuotedStringLiteralType {
+		return p.unquotedStringLiteral()
+	}
+
 	token, err := p.eat(QuotedStringLiteralType)
 	if err != nil {
 		return nil, err
 	}
 
-	replacer := strings.NewReplacer(`"`, "", "'", "")
-
-	return replacer.Replace(token.Value), nil
+	return &Literal{
+		Value:    tokenizer.Unquote(token.Value),
+		Kind:     StringLiteralKind,
+		Position: token.Position,
+	}, nil
 }
 
 /*
  * <numeric_literal>
  * : <INT>
  * | <FLOAT>
+ * | "$dec(" <DECIMAL> ")"
  * .
  */
-func (p *Parser) numericLiteral() (interface{}, error) {
+func (p *state) numericLiteral() (*Literal, error) {
+	if p.atPlaceholder() {
+		return p.placeholder()
+	}
+
+	if p.lookahead != nil && p.lookahead.Type == DecimalLiteralType {
+		token, err := p.eat(DecimalLiteralType)
+		if err != nil {
+			return nil, err
+		}
+
+		number := strings.TrimSuffix(strings.TrimPrefix(token.Value, "$dec("), ")")
+
+		value, kind, err := decimalValue(number)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Literal{Value: value, Kind: kind, Position: token.Position}, nil
+	}
+
 	token, err := p.eat(NumberLiteralType)
 	if err != nil {
 		return nil, err
 	}
 
-	if strings.Contains(token.Value, ".") {
-		var value float64
-		value, err = strconv.ParseFloat(token.Value, float64Size)
+	value, kind, err := numericValue(token.Value, p.numeric)
+	if err != nil {
+		return nil, err
+	}
 
-		return value, errors.Wrap(err, "failed to parse float value")
+	return &Literal{Value: value, Kind: kind, Position: token.Position}, nil
+}
+
+/*
+ * <date_literal>
+ * : "$date(" <DATE> ")"
+ * | "$now(" <OFFSET> ")"
+ * .
+ */
+func (p *state) dateLiteral() (*Literal, error) {
+	if p.lookahead != nil && p.lookahead.Type == RelativeDateLiteralType {
+		token, err := p.eat(RelativeDateLiteralType)
+		if err != nil {
+			return nil, err
+		}
+
+		offset := strings.TrimSuffix(strings.TrimPrefix(token.Value, "$now("), ")")
+
+		date, err := parseRelativeDate(offset, p.clock())
+		if err != nil {
+			return nil, fmt.Errorf("could not parse $now '%s': %w", offset, err)
+		}
+
+		return &Literal{Value: date, Kind: DateLiteralKind, Position: token.Position}, nil
 	}
 
-	var value int64
-	value, err = strconv.ParseInt(token.Value, intBase, int64Size)
+	token, err := p.eat(DateLiteralType)
+	if err != nil {
+		return nil, err
+	}
+
+	value := strings.TrimSuffix(strings.TrimPrefix(token.Value, "$date("), ")")
+
+	date, err := parseDate(value)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse $date '%s': %w", value, err)
+	}
+
+	return &Literal{Value: date, Kind: DateLiteralKind, Position: token.Position}, nil
+}
+
+/*
+ * <placeholder>
+ * : ":" <NAME>
+ * .
+ */
+func (p *state) placeholder() (*Literal, error) {
+	token, err := p.eat(PlaceholderLiteralType)
+	if err != nil {
+		return nil, err
+	}
 
-	return value, errors.Wrap(err, "failed to parse int value")
+	return &Literal{
+		Value:    strings.TrimPrefix(token.Value, ":"),
+		Kind:     PlaceholderLiteralKind,
+		Position: token.Position,
+	}, nil
+}
+
+// placeholderArgument sets a placeholder that is bound to a list as argument.
+func (p *state) placeholderArgument(node *ComparisonNode) error {
+	literal, err := p.placeholder()
+	if err != nil {
+		return err
+	}
+
+	node.Argument = *literal
+
+	return nil
+}
+
+// atPlaceholder checks if the lookahead is a placeholder of a template.
+func (p *state) atPlaceholder() bool {
+	return p.lookahead != nil && p.lookahead.Type == PlaceholderLiteralType
 }
 
 /*
  * <literal_list>
- * : <quoted_string_literal> "," <literal_list>
- * | <numeric_literal> "," <literal_list>
+ * : <literal> "," <literal_list>
+ * | <literal>
  * .
  */
-func (p *Parser) literalList() (bson.A, error) {
-	items := bson.A{}
+func (p *state) literalList() ([]Literal, error) {
+	items := []Literal{}
 
 	body, err := p.literal()
 	if err != nil {
 		return nil, err
 	}
 
-	items = append(items, body)
+	items = append(items, *body)
 
-	for p.lookahead.Type == OrCompositeType {
+	for p.lookahead != nil && p.lookahead.Type == OrCompositeType {
 		_, err := p.eat(OrCompositeType)
 		if err != nil {
 			return nil, err
@@ -550,7 +1248,11 @@ func (p *Parser) literalList() (bson.A, error) {
 			return nil, err
 		}
 
-		items = append(items, body)
+		items = append(items, *body)
+
+		if err := check(MaxListLengthLimit, p.limits.MaxListLength, len(items), body.Position); err != nil {
+			return nil, err
+		}
 	}
 
 	return items, nil
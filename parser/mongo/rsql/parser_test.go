@@ -3,10 +3,13 @@ package rsql
 
 import (
 	"context"
-	"regexp"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
 	testutil "github.com/StevenCyb/goapiutils/parser/mongo/test_util"
 	"github.com/StevenCyb/goapiutils/parser/tokenizer"
 	"github.com/stretchr/testify/require"
@@ -110,10 +113,10 @@ func TestQueryParsingWithDifferentLiterals(t *testing.T) {
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
 			`coll=in=(1, "a", "b",2)`,
-			bson.D{bson.E{Key: "coll", Value: bson.E{
+			bson.D{bson.E{Key: "coll", Value: bson.D{bson.E{
 				Key:   "$in",
 				Value: bson.A{int64(1), "a", "b", int64(2)},
-			}}},
+			}}}},
 		)
 	})
 }
@@ -151,7 +154,7 @@ func TestQueryParsingWithSingleComparisonOperation(t *testing.T) {
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
 			`msg=sw="LOG_"`,
-			bson.D{bson.E{Key: "msg", Value: *regexp.MustCompile("^LOG_")}},
+			bson.D{bson.E{Key: "msg", Value: primitive.Regex{Pattern: "^LOG_"}}},
 		)
 	})
 
@@ -161,7 +164,67 @@ func TestQueryParsingWithSingleComparisonOperation(t *testing.T) {
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
 			`word=ew="ed"`,
-			bson.D{bson.E{Key: "word", Value: *regexp.MustCompile("ed$")}},
+			bson.D{bson.E{Key: "word", Value: primitive.Regex{Pattern: "ed$"}}},
+		)
+	})
+
+	t.Run("=sw=WithSpecialCharacters_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`host=sw="a.b(c"`,
+			bson.D{bson.E{Key: "host", Value: primitive.Regex{Pattern: `^a\.b\(c`}}},
+		)
+	})
+
+	t.Run("=like=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`file=like="img_*.jpg"`,
+			bson.D{bson.E{Key: "file", Value: primitive.Regex{Pattern: `^img_.*\.jpg$`}}},
+		)
+	})
+
+	t.Run("=contains=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`title=contains="go+"`,
+			bson.D{bson.E{Key: "title", Value: primitive.Regex{Pattern: `go\+`}}},
+		)
+	})
+
+	t.Run("=isw=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`name=isw="st"`,
+			bson.D{bson.E{Key: "name", Value: primitive.Regex{Pattern: "^st", Options: "i"}}},
+		)
+	})
+
+	t.Run("=iew=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`name=iew="en"`,
+			bson.D{bson.E{Key: "name", Value: primitive.Regex{Pattern: "en$", Options: "i"}}},
+		)
+	})
+
+	t.Run("=ieq=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`name=ieq="Steven"`,
+			bson.D{bson.E{Key: "name", Value: primitive.Regex{Pattern: "^Steven$", Options: "i"}}},
 		)
 	})
 
@@ -221,16 +284,26 @@ func TestQueryParsingWithSingleComparisonOperation(t *testing.T) {
 		)
 	})
 
+	t.Run("=gt=WithString_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`name=gt="m"`,
+			errs.NewErrUnexpectedTokenType(11, "QUOTED_STRING_LITERAL", "NUMERIC_LITERAL"),
+		)
+	})
+
 	t.Run("=in=_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
 			`coll=in=(1, "a", "b",2)`,
-			bson.D{bson.E{Key: "coll", Value: bson.E{
+			bson.D{bson.E{Key: "coll", Value: bson.D{bson.E{
 				Key:   "$in",
 				Value: bson.A{int64(1), "a", "b", int64(2)},
-			}}},
+			}}}},
 		)
 	})
 
@@ -240,334 +313,1330 @@ func TestQueryParsingWithSingleComparisonOperation(t *testing.T) {
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
 			`coll=out=(1, "a", "b",2)`,
-			bson.D{bson.E{Key: "coll", Value: bson.E{
+			bson.D{bson.E{Key: "coll", Value: bson.D{bson.E{
 				Key:   "$nin",
 				Value: bson.A{int64(1), "a", "b", int64(2)},
-			}}},
+			}}}},
 		)
 	})
 }
 
-func TestQueryParsingWithMultipleComparisonOperation(t *testing.T) {
+func TestQueryParsingWithExistenceAndNull(t *testing.T) {
 	t.Parallel()
 
-	t.Run("WithSingleAnd_Success", func(t *testing.T) {
+	t.Run("=exists=_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
-			`firstName=="steven";age=ge=18`,
-			bson.D{
-				bson.E{Key: "$and", Value: bson.A{
-					bson.D{
-						bson.E{Key: "firstName", Value: "steven"},
-					},
-					bson.D{
-						bson.E{Key: "age", Value: bson.D{
-							bson.E{Key: "$gte", Value: int64(18)},
-						}},
-					},
-				}},
-			},
+			`deleted_at=exists=false`,
+			bson.D{bson.E{Key: "deleted_at", Value: bson.D{bson.E{Key: "$exists", Value: false}}}},
 		)
 	})
 
-	t.Run("WithSingleOr_Success", func(t *testing.T) {
+	t.Run("=null=true_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
-			`level=="error",level=="warning"`,
-			bson.D{
-				bson.E{Key: "$or", Value: bson.A{
-					bson.D{
-						bson.E{Key: "level", Value: "error"},
-					},
-					bson.D{
-						bson.E{Key: "level", Value: "warning"},
-					},
-				}},
-			},
+			`deleted_at=null=true`,
+			bson.D{bson.E{Key: "deleted_at", Value: bson.D{bson.E{Key: "$type", Value: "null"}}}},
 		)
 	})
 
-	t.Run("WithAndChain_Success", func(t *testing.T) {
+	t.Run("=null=false_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
-			`firstName=="steven";age=ge=18;gender=="male"`,
-			bson.D{
-				bson.E{Key: "$and", Value: bson.A{
-					bson.D{
-						bson.E{Key: "firstName", Value: "steven"},
-					},
-					bson.D{
-						bson.E{Key: "age", Value: bson.D{
-							bson.E{Key: "$gte", Value: int64(18)},
-						}},
-					},
-					bson.D{
-						bson.E{Key: "gender", Value: "male"},
-					},
-				}},
-			},
+			`deleted_at=null=false`,
+			bson.D{bson.E{Key: "deleted_at", Value: bson.D{
+				bson.E{Key: "$not", Value: bson.D{bson.E{Key: "$type", Value: "null"}}},
+			}}},
 		)
 	})
 
-	t.Run("WithOrChain_Success", func(t *testing.T) {
+	t.Run("==null_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
-			`level=="panic",level=="error",level=="warning"`,
+			`deleted_at==NULL;nullable==1`,
 			bson.D{
-				bson.E{Key: "$or", Value: bson.A{
-					bson.D{
-						bson.E{Key: "level", Value: "panic"},
-					},
-					bson.D{
-						bson.E{Key: "level", Value: "error"},
-					},
-					bson.D{
-						bson.E{Key: "level", Value: "warning"},
-					},
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{bson.E{Key: "deleted_at", Value: nil}},
+					bson.D{bson.E{Key: "nullable", Value: int64(1)}},
 				}},
 			},
 		)
 	})
 
-	t.Run("WithMixed_Success", func(t *testing.T) {
+	t.Run("!=null_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
-			`a==1,a==2,a==3,b==1;c==1`,
-			bson.D{
-				bson.E{Key: "$or", Value: bson.A{
-					bson.D{
-						bson.E{Key: "a", Value: int64(1)},
-					},
-					bson.D{
-						bson.E{Key: "a", Value: int64(2)},
-					},
-					bson.D{
-						bson.E{Key: "a", Value: int64(3)},
-					},
-					bson.D{
-						bson.E{Key: "$and", Value: bson.A{
-							bson.D{
-								bson.E{Key: "b", Value: int64(1)},
-							},
-							bson.D{
-								bson.E{Key: "c", Value: int64(1)},
-							},
-						}},
-					},
-				}},
-			},
+			`deleted_at!=null`,
+			bson.D{bson.E{Key: "deleted_at", Value: bson.D{bson.E{Key: "$ne", Value: nil}}}},
 		)
+	})
+
+	t.Run("=in=WithNull_Success", func(t *testing.T) {
+		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
-			`a==1;b==1,a==2;b==2`,
-			bson.D{
-				bson.E{Key: "$and", Value: bson.A{
-					bson.D{
-						bson.E{Key: "a", Value: int64(1)},
-					},
-					bson.D{
-						bson.E{Key: "$or", Value: bson.A{
-							bson.D{
-								bson.E{Key: "b", Value: int64(1)},
-							},
-							bson.D{
-								bson.E{Key: "$and", Value: bson.A{
-									bson.D{
-										bson.E{Key: "a", Value: int64(2)},
-									},
-									bson.D{
-										bson.E{Key: "b", Value: int64(2)},
-									},
-								}},
-							},
-						}},
-					},
-				}},
-			},
+			`status=in=(null,"active")`,
+			bson.D{bson.E{Key: "status", Value: bson.D{bson.E{
+				Key:   "$in",
+				Value: bson.A{nil, "active"},
+			}}}},
 		)
 	})
 
-	t.Run("WithContext_Success", func(t *testing.T) {
+	t.Run("=exists=WithNonBool_Fail", func(t *testing.T) {
 		t.Parallel()
 
-		testutil.ExecuteSuccessTest(t,
+		testutil.ExecuteFailedTest(t,
 			NewParser(nil),
-			`(a==1;b==1),(a==2;b==2),(a==3;b==3)`,
-			bson.D{
-				bson.E{Key: "$or", Value: bson.A{
-					bson.D{
-						bson.E{Key: "$and", Value: bson.A{
-							bson.D{
-								bson.E{Key: "a", Value: int64(1)},
-							},
-							bson.D{
-								bson.E{Key: "b", Value: int64(1)},
-							},
-						}},
-					},
-					bson.D{
-						bson.E{Key: "$and", Value: bson.A{
-							bson.D{
-								bson.E{Key: "a", Value: int64(2)},
-							},
-							bson.D{
-								bson.E{Key: "b", Value: int64(2)},
-							},
-						}},
-					},
-					bson.D{
-						bson.E{Key: "$and", Value: bson.A{
-							bson.D{
-								bson.E{Key: "a", Value: int64(3)},
-							},
-							bson.D{
-								bson.E{Key: "b", Value: int64(3)},
-							},
-						}},
-					},
-				}},
-			},
+			`deleted_at=exists=1`,
+			errs.NewErrUnexpectedTokenType(19, "NUMERIC_LITERAL", "BOOL_LITERAL"),
 		)
 	})
+}
 
-	t.Run("WithNestedContext_Success", func(t *testing.T) {
+func TestQueryParsingWithDates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("$date_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
 			NewParser(nil),
-			`(a==1;b==1),((a==2,b==2);(a==3,b==3))`,
+			`created_at=ge=$date(2024-01-01T00:00:00Z);created_at=lt=$date(2024-02-01)`,
 			bson.D{
-				bson.E{Key: "$or", Value: bson.A{
-					bson.D{
-						bson.E{Key: "$and", Value: bson.A{
-							bson.D{
-								bson.E{Key: "a", Value: int64(1)},
-							},
-							bson.D{
-								bson.E{Key: "b", Value: int64(1)},
-							},
-						}},
-					},
-					bson.D{
-						bson.E{Key: "$and", Value: bson.A{
-							bson.D{
-								bson.E{Key: "$or", Value: bson.A{
-									bson.D{
-										bson.E{Key: "a", Value: int64(2)},
-									},
-									bson.D{
-										bson.E{Key: "b", Value: int64(2)},
-									},
-								}},
-							},
-							bson.D{
-								bson.E{Key: "$or", Value: bson.A{
-									bson.D{
-										bson.E{Key: "a", Value: int64(3)},
-									},
-									bson.D{
-										bson.E{Key: "b", Value: int64(3)},
-									},
-								}},
-							},
-						}},
-					},
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{bson.E{Key: "created_at", Value: bson.D{
+						bson.E{Key: "$gte", Value: primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+					}}},
+					bson.D{bson.E{Key: "created_at", Value: bson.D{
+						bson.E{Key: "$lt", Value: primitive.NewDateTimeFromTime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))},
+					}}},
 				}},
 			},
 		)
 	})
-}
-
-func TestQueryParsingFailCases(t *testing.T) {
-	t.Parallel()
 
-	t.Run("WithUnknownCompareOperation_Fail", func(t *testing.T) {
+	t.Run("$now_Success", func(t *testing.T) {
 		t.Parallel()
 
-		testutil.ExecuteFailedTest(t,
-			NewParser(nil),
-			`x=7`,
-			errs.NewErrUnexpectedToken(1, "="),
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil).SetClock(clock),
+			`created_at=gt=$now(-7d)`,
+			bson.D{bson.E{Key: "created_at", Value: bson.D{
+				bson.E{Key: "$gt", Value: primitive.NewDateTimeFromTime(now.AddDate(0, 0, -7))},
+			}}},
 		)
 	})
 
-	t.Run("WithIncompleteExpression_Fail", func(t *testing.T) {
+	t.Run("$nowInList_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil).SetClock(clock),
+			`created_at=in=($now(),$date(2024-01-01))`,
+			bson.D{bson.E{Key: "created_at", Value: bson.D{bson.E{
+				Key: "$in",
+				Value: bson.A{
+					primitive.NewDateTimeFromTime(now),
+					primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+				},
+			}}}},
+		)
+	})
+
+	t.Run("WithInvalidDate_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).Parse(`created_at=gt=$date(yesterday)`)
+		require.ErrorIs(t, err, ErrInvalidDate)
+	})
+
+	t.Run("WithInvalidOffset_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).Parse(`created_at=gt=$now(-7x)`)
+		require.ErrorIs(t, err, ErrInvalidDate)
+	})
+}
+
+func TestQueryParsingWithArrayOperators(t *testing.T) {
+	t.Parallel()
+
+	t.Run("=all=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`roles=all=("dev","admin")`,
+			bson.D{bson.E{Key: "roles", Value: bson.D{bson.E{Key: "$all", Value: bson.A{"dev", "admin"}}}}},
+		)
+	})
+
+	t.Run("=size=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`roles=size=3`,
+			bson.D{bson.E{Key: "roles", Value: bson.D{bson.E{Key: "$size", Value: int64(3)}}}},
+		)
+	})
+
+	t.Run("=size=WithFloat_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`roles=size=1.5`,
+			errs.NewErrUnexpectedToken(11, "1.5"),
+		)
+	})
+
+	t.Run("=em=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`items=em=(sku=="x";qty=gt=2);active==true`,
+			bson.D{
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{bson.E{Key: "items", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+						bson.E{Key: "$and", Value: bson.A{
+							bson.D{bson.E{Key: "sku", Value: "x"}},
+							bson.D{bson.E{Key: "qty", Value: bson.D{bson.E{Key: "$gt", Value: int64(2)}}}},
+						}},
+					}}}}},
+					bson.D{bson.E{Key: "active", Value: true}},
+				}},
+			},
+		)
+	})
+
+	t.Run("=em=Nested_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`orders=em=(items=em=(sku=="x"))`,
+			bson.D{bson.E{Key: "orders", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+				bson.E{Key: "items", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+					bson.E{Key: "sku", Value: "x"},
+				}}}},
+			}}}}},
+		)
+	})
+
+	t.Run("=em=WithPolicy_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "items", "items.sku", "sku")),
+			`items=em=(sku=="x");sku=="y"`,
+			bson.D{
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{bson.E{Key: "items", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+						bson.E{Key: "sku", Value: "x"},
+					}}}}},
+					bson.D{bson.E{Key: "sku", Value: "y"}},
+				}},
+			},
+		)
+	})
+
+	t.Run("=em=WithPolicy_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "items", "sku")),
+			`items=em=(sku=="x")`,
+			errs.NewErrPolicyViolation("items.sku"),
+		)
+	})
+
+	t.Run("=em=WithoutContext_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`items=em=sku=="x"`,
+			errs.NewErrUnexpectedTokenType(12, "FIELD_NAME", "("),
+		)
+	})
+}
+
+func TestQueryParsingWithNegation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithOperatorExpression_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`!age=lt=18`,
+			bson.D{bson.E{Key: "age", Value: bson.D{
+				bson.E{Key: "$not", Value: bson.D{bson.E{Key: "$lt", Value: int64(18)}}},
+			}}},
+		)
+	})
+
+	t.Run("WithRegex_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`!name=sw="a"`,
+			bson.D{bson.E{Key: "name", Value: bson.D{
+				bson.E{Key: "$not", Value: primitive.Regex{Pattern: "^a"}},
+			}}},
+		)
+	})
+
+	t.Run("WithEqual_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`!name=="a"`,
+			bson.D{bson.E{Key: "$nor", Value: bson.A{bson.D{bson.E{Key: "name", Value: "a"}}}}},
+		)
+	})
+
+	t.Run("WithGroup_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`!(status=="a";age=lt=18)`,
+			bson.D{bson.E{Key: "$nor", Value: bson.A{bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "status", Value: "a"}},
+				bson.D{bson.E{Key: "age", Value: bson.D{bson.E{Key: "$lt", Value: int64(18)}}}},
+			}}}}}},
+		)
+	})
+
+	t.Run("WithPrecedence_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`!a==1,b==2`,
+			bson.D{bson.E{Key: "$or", Value: bson.A{
+				bson.D{bson.E{Key: "$nor", Value: bson.A{bson.D{bson.E{Key: "a", Value: int64(1)}}}}},
+				bson.D{bson.E{Key: "b", Value: int64(2)}},
+			}}},
+		)
+	})
+
+	t.Run("WithDoubleNegation_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`!!age=lt=18`,
+			bson.D{bson.E{Key: "$nor", Value: bson.A{bson.D{bson.E{Key: "age", Value: bson.D{
+				bson.E{Key: "$not", Value: bson.D{bson.E{Key: "$lt", Value: int64(18)}}},
+			}}}}}},
+		)
+	})
+
+	t.Run("WithNotEqual_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`a!=1`,
+			bson.D{bson.E{Key: "a", Value: bson.D{bson.E{Key: "$ne", Value: int64(1)}}}},
+		)
+	})
+
+	t.Run("WithMissingTerm_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`a==1;!`,
+			errs.NewErrUnexpectedInputEnd("FIELD_NAME"),
+		)
+	})
+
+	t.Run("WithUnexpectedComposite_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`!;a==1`,
+			errs.NewErrUnexpectedTokenType(2, ";", "FIELD_NAME"),
+		)
+	})
+}
+
+func TestQueryParsingWithMultipleComparisonOperation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithSingleAnd_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`firstName=="steven";age=ge=18`,
+			bson.D{
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{
+						bson.E{Key: "firstName", Value: "steven"},
+					},
+					bson.D{
+						bson.E{Key: "age", Value: bson.D{
+							bson.E{Key: "$gte", Value: int64(18)},
+						}},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithSingleOr_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`level=="error",level=="warning"`,
+			bson.D{
+				bson.E{Key: "$or", Value: bson.A{
+					bson.D{
+						bson.E{Key: "level", Value: "error"},
+					},
+					bson.D{
+						bson.E{Key: "level", Value: "warning"},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithAndChain_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`firstName=="steven";age=ge=18;gender=="male"`,
+			bson.D{
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{
+						bson.E{Key: "firstName", Value: "steven"},
+					},
+					bson.D{
+						bson.E{Key: "age", Value: bson.D{
+							bson.E{Key: "$gte", Value: int64(18)},
+						}},
+					},
+					bson.D{
+						bson.E{Key: "gender", Value: "male"},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithOrChain_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`level=="panic",level=="error",level=="warning"`,
+			bson.D{
+				bson.E{Key: "$or", Value: bson.A{
+					bson.D{
+						bson.E{Key: "level", Value: "panic"},
+					},
+					bson.D{
+						bson.E{Key: "level", Value: "error"},
+					},
+					bson.D{
+						bson.E{Key: "level", Value: "warning"},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithMixed_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`a==1,a==2,a==3,b==1;c==1`,
+			bson.D{
+				bson.E{Key: "$or", Value: bson.A{
+					bson.D{
+						bson.E{Key: "a", Value: int64(1)},
+					},
+					bson.D{
+						bson.E{Key: "a", Value: int64(2)},
+					},
+					bson.D{
+						bson.E{Key: "a", Value: int64(3)},
+					},
+					bson.D{
+						bson.E{Key: "$and", Value: bson.A{
+							bson.D{
+								bson.E{Key: "b", Value: int64(1)},
+							},
+							bson.D{
+								bson.E{Key: "c", Value: int64(1)},
+							},
+						}},
+					},
+				}},
+			},
+		)
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`a==1;b==1,a==2;b==2`,
+			bson.D{
+				bson.E{Key: "$and", Value: bson.A{
+					bson.D{
+						bson.E{Key: "a", Value: int64(1)},
+					},
+					bson.D{
+						bson.E{Key: "$or", Value: bson.A{
+							bson.D{
+								bson.E{Key: "b", Value: int64(1)},
+							},
+							bson.D{
+								bson.E{Key: "$and", Value: bson.A{
+									bson.D{
+										bson.E{Key: "a", Value: int64(2)},
+									},
+									bson.D{
+										bson.E{Key: "b", Value: int64(2)},
+									},
+								}},
+							},
+						}},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithContext_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`(a==1;b==1),(a==2;b==2),(a==3;b==3)`,
+			bson.D{
+				bson.E{Key: "$or", Value: bson.A{
+					bson.D{
+						bson.E{Key: "$and", Value: bson.A{
+							bson.D{
+								bson.E{Key: "a", Value: int64(1)},
+							},
+							bson.D{
+								bson.E{Key: "b", Value: int64(1)},
+							},
+						}},
+					},
+					bson.D{
+						bson.E{Key: "$and", Value: bson.A{
+							bson.D{
+								bson.E{Key: "a", Value: int64(2)},
+							},
+							bson.D{
+								bson.E{Key: "b", Value: int64(2)},
+							},
+						}},
+					},
+					bson.D{
+						bson.E{Key: "$and", Value: bson.A{
+							bson.D{
+								bson.E{Key: "a", Value: int64(3)},
+							},
+							bson.D{
+								bson.E{Key: "b", Value: int64(3)},
+							},
+						}},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithNestedContext_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`(a==1;b==1),((a==2,b==2);(a==3,b==3))`,
+			bson.D{
+				bson.E{Key: "$or", Value: bson.A{
+					bson.D{
+						bson.E{Key: "$and", Value: bson.A{
+							bson.D{
+								bson.E{Key: "a", Value: int64(1)},
+							},
+							bson.D{
+								bson.E{Key: "b", Value: int64(1)},
+							},
+						}},
+					},
+					bson.D{
+						bson.E{Key: "$and", Value: bson.A{
+							bson.D{
+								bson.E{Key: "$or", Value: bson.A{
+									bson.D{
+										bson.E{Key: "a", Value: int64(2)},
+									},
+									bson.D{
+										bson.E{Key: "b", Value: int64(2)},
+									},
+								}},
+							},
+							bson.D{
+								bson.E{Key: "$or", Value: bson.A{
+									bson.D{
+										bson.E{Key: "a", Value: int64(3)},
+									},
+									bson.D{
+										bson.E{Key: "b", Value: int64(3)},
+									},
+								}},
+							},
+						}},
+					},
+				}},
+			},
+		)
+	})
+}
+
+func TestQueryParsingFailCases(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithUnknownCompareOperation_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`x=7`,
+			errs.NewErrUnexpectedToken(1, "="),
+		)
+	})
+
+	t.Run("WithIncompleteExpression_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`x==7;`,
+			errs.NewErrUnexpectedInputEnd("FIELD_NAME"),
+		)
+	})
+
+	t.Run("WithWrongCompareOperation_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`x=in=3`,
+			errs.NewErrUnexpectedTokenType(6, "NUMERIC_LITERAL", "("),
+		)
+	})
+
+	t.Run("WithNotClosedContext_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`(x==7`,
+			errs.NewErrUnexpectedInputEnd(")"),
+		)
+	})
+}
+
+func TestQueryParsingWithPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithAllowedFieldName_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(
+				tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name", "age")),
+			`name=="steven",age=ge=18`,
+			bson.D{
+				bson.E{Key: "$or", Value: bson.A{
+					bson.D{
+						bson.E{Key: "name", Value: "steven"},
+					},
+					bson.D{
+						bson.E{Key: "age", Value: bson.D{
+							bson.E{Key: "$gte", Value: int64(18)},
+						}},
+					},
+				}},
+			},
+		)
+	})
+
+	t.Run("WithDisallowedFieldNames_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(
+				tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name", "age")),
+			`name=="steven",age=ge=18,gender="male"`,
+			errs.NewErrPolicyViolation("gender"),
+		)
+	})
+}
+
+func TestQueryParsingWithFieldNameValidation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithOperatorFieldName_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`a==1;$where=="sleep(1000)"`,
+			errs.NewErrInvalidFieldName(5, "$where", tokenizer.OperatorSegmentReason),
+		)
+	})
+
+	t.Run("WithNestedOperatorFieldName_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(nil),
+			`user.$expr==1`,
+			errs.NewErrInvalidFieldName(0, "user.$expr", tokenizer.OperatorSegmentReason),
+		)
+	})
+
+	t.Run("WithEmptySegment_Fail", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteFailedTest(t,
 			NewParser(nil),
-			`x==7;`,
-			errs.NewErrUnexpectedInputEnd("FIELD_NAME"),
+			`user.==1`,
+			errs.NewErrInvalidFieldName(0, "user.", tokenizer.EmptySegmentReason),
 		)
 	})
 
-	t.Run("WithWrongCompareOperation_Fail", func(t *testing.T) {
+	t.Run("WithControlCharacter_Fail", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteFailedTest(t,
 			NewParser(nil),
-			`x=in=3`,
-			errs.NewErrUnexpectedTokenType(6, "NUMERIC_LITERAL", "("),
+			"us\x00er==1",
+			errs.NewErrInvalidFieldName(0, "us\x00er", tokenizer.ControlCharacterReason),
 		)
 	})
 
-	t.Run("WithNotClosedContext_Fail", func(t *testing.T) {
+	t.Run("WithUnsafeFieldNames_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil).AllowUnsafeFieldNames(),
+			`$where=="true"`,
+			bson.D{bson.E{Key: "$where", Value: "true"}},
+		)
+	})
+}
+
+//nolint:funlen
+func TestQueryParsingWithFieldPolicies(t *testing.T) {
+	t.Parallel()
+
+	policies := FieldPolicies{
+		"description": {Operators: []Operator{StartsWithOperator}},
+		"tenant_id":   {Deny: true},
+		"status":      {Values: []interface{}{"active", "archived"}},
+		"age":         {Kinds: []LiteralKind{IntLiteralKind}, Values: []interface{}{18, 21}},
+		"items.sku":   {Operators: []Operator{EqualOperator}},
+	}
+
+	t.Run("WithAllowedUsage_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil).SetFieldPolicies(policies),
+			`description=sw="new";status=in=("active","archived");age==18;age=exists=true;items=em=(sku=="x")`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "description", Value: primitive.Regex{Pattern: "^new"}}},
+				bson.D{bson.E{Key: "status", Value: bson.D{bson.E{Key: "$in", Value: bson.A{"active", "archived"}}}}},
+				bson.D{bson.E{Key: "age", Value: int64(18)}},
+				bson.D{bson.E{Key: "age", Value: bson.D{bson.E{Key: "$exists", Value: true}}}},
+				bson.D{bson.E{Key: "items", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+					bson.E{Key: "sku", Value: "x"},
+				}}}}},
+			}}},
+		)
+	})
+
+	t.Run("WithViolations_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]error{
+			`description=="new"`:   errs.NewErrFieldPolicyViolation(0, "description", "==", "operator is not allowed"),
+			`a==1,!(tenant_id==1)`: errs.NewErrFieldPolicyViolation(7, "tenant_id", "==", "field is not allowed"),
+			`tenant_id=em=(a==1)`:  errs.NewErrFieldPolicyViolation(0, "tenant_id", "=em=", "field is not allowed"),
+			`tenant_id.id=="x"`:    errs.NewErrFieldPolicyViolation(0, "tenant_id.id", "==", "field is not allowed"),
+			`a==1;tenant_id.a==1`:  errs.NewErrFieldPolicyViolation(5, "tenant_id.a", "==", "field is not allowed"),
+			`!tenant_id.a.b=gt=1`:  errs.NewErrFieldPolicyViolation(1, "tenant_id.a.b", "=gt=", "field is not allowed"),
+			`status=in=("active","deleted")`: errs.NewErrFieldPolicyViolation(0, "status", "=in=",
+				"value 'deleted' is not allowed"),
+			`status=sw="act"`: errs.NewErrFieldPolicyViolation(0, "status", "=sw=", "value 'act' is not allowed"),
+			`age==18.5`:       errs.NewErrFieldPolicyViolation(0, "age", "==", "literal of kind 'FLOAT' is not allowed"),
+			`age==30`:         errs.NewErrFieldPolicyViolation(0, "age", "==", "value '30' is not allowed"),
+			`items=em=(sku!="x")`: errs.NewErrFieldPolicyViolation(10, "items.sku", "!=",
+				"operator is not allowed"),
+		} {
+			_, err := NewParser(nil).SetFieldPolicies(policies).Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+
+	t.Run("WithFieldMapping_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		// policies use the names of the query and not the mapped ones
+		_, err := NewParser(nil).
+			SetFieldMapping(fieldmap.NewMapping(map[string]string{"tenant_id": "meta.tenant"})).
+			SetFieldPolicies(policies).
+			Parse(`tenant_id==1`)
+		require.Equal(t, errs.NewErrFieldPolicyViolation(0, "tenant_id", "==", "field is not allowed"), err)
+	})
+}
+
+func TestQueryParsingWithFieldMapping(t *testing.T) {
+	t.Parallel()
+
+	mapping := fieldmap.NewMapping(map[string]string{
+		"createdAt": "meta.created_at",
+		"items":     "meta.items",
+		"owner":     "_owner",
+	})
+
+	t.Run("WithMappedFieldNames_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil).SetFieldMapping(mapping),
+			`createdAt=gt=1;items=em=(sku=="x");owner.name=="a"`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "meta.created_at", Value: bson.D{bson.E{Key: "$gt", Value: int64(1)}}}},
+				bson.D{bson.E{Key: "meta.items", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+					bson.E{Key: "sku", Value: "x"},
+				}}}}},
+				bson.D{bson.E{Key: "_owner.name", Value: "a"}},
+			}}},
+		)
+	})
+
+	t.Run("WithAlias_Success", func(t *testing.T) {
+		t.Parallel()
+
+		node, err := NewParser(nil).SetFieldMapping(mapping).ParseAST(`createdAt==1`)
+		require.NoError(t, err)
+		require.Equal(t, &ComparisonNode{
+			Field: "meta.created_at", Alias: "createdAt", Operator: EqualOperator, Position: 0,
+			Argument: Literal{Value: int64(1), Kind: IntLiteralKind, Position: 11},
+		}, node)
+	})
+
+	t.Run("WithUnmappedFieldName_Fail", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteFailedTest(t,
-			NewParser(nil),
-			`(x==7`,
-			errs.NewErrUnexpectedInputEnd(")"),
+			NewParser(nil).SetFieldMapping(mapping),
+			`createdAt=gt=1;meta.created_at=gt=1`,
+			errs.NewErrUnmappedField(15, "meta.created_at"),
 		)
 	})
+
+	t.Run("WithPolicyOnUnmappedNames_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "items", "items.sku")).SetFieldMapping(mapping),
+			`items=em=(sku=="x");createdAt=gt=1`,
+			errs.NewErrPolicyViolation("createdAt"),
+		)
+	})
+
+	t.Run("WithSmartParser", func(t *testing.T) {
+		t.Parallel()
+
+		type meta struct {
+			CreatedAt time.Time `bson:"created_at"`
+		}
+
+		type doc struct {
+			Meta meta `bson:"meta"`
+		}
+
+		parser, err := NewSmartParser(reflect.TypeOf(doc{}))
+		require.NoError(t, err)
+
+		parser.SetFieldMapping(mapping)
+
+		_, err = parser.Parse(`createdAt=gt="2024-01-01"`)
+		require.NoError(t, err)
+
+		_, err = parser.Parse(`createdAt==true`)
+		require.Equal(t, LiteralMismatchError{
+			field: "createdAt", kind: BoolLiteralKind, fieldType: "time.Time", position: 11,
+		}, err)
+	})
 }
 
-func TestQueryParsingWithPolicy(t *testing.T) {
+func TestQueryParsingWithLimits(t *testing.T) {
 	t.Parallel()
 
-	t.Run("WithAllowedFieldName_Success", func(t *testing.T) {
+	t.Run("WithinLimits_Success", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).SetLimits(Limits{
+			MaxDepth: 3, MaxComparisons: 3, MaxListLength: 2, MaxQueryLength: 36, MaxRegexLength: 2,
+		}).Parse(`!(a=in=(1,2);items=em=(b=like="x*"))`)
+		require.NoError(t, err)
+	})
+
+	testCases := map[string]struct {
+		limits   Limits
+		query    string
+		expected error
+	}{
+		"MaxDepthWithContext_Fail": {
+			Limits{MaxDepth: 1}, `(a==1;(b==2))`, errs.NewErrLimitExceeded(6, MaxDepthLimit, 1),
+		},
+		"MaxDepthWithNegation_Fail": {
+			Limits{MaxDepth: 2}, `!!!a==1`, errs.NewErrLimitExceeded(2, MaxDepthLimit, 2),
+		},
+		"MaxDepthWithElemMatch_Fail": {
+			Limits{MaxDepth: 1}, `(items=em=(a==1))`, errs.NewErrLimitExceeded(10, MaxDepthLimit, 1),
+		},
+		"MaxComparisons_Fail": {
+			Limits{MaxComparisons: 2}, `a==1,b==2,c==3`, errs.NewErrLimitExceeded(10, MaxComparisonsLimit, 2),
+		},
+		"MaxListLength_Fail": {
+			Limits{MaxListLength: 2}, `a=in=(1,2,3)`, errs.NewErrLimitExceeded(10, MaxListLengthLimit, 2),
+		},
+		"MaxQueryLength_Fail": {
+			Limits{MaxQueryLength: 5}, `a==123`, errs.NewErrLimitExceeded(0, MaxQueryLengthLimit, 5),
+		},
+		"MaxRegexLength_Fail": {
+			Limits{MaxRegexLength: 3}, `a=like="abcd"`, errs.NewErrLimitExceeded(7, MaxRegexLengthLimit, 3),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteFailedTest(t, NewParser(nil).SetLimits(testCase.limits), testCase.query, testCase.expected)
+		})
+	}
+}
+
+//nolint:funlen
+func TestQueryParsingWithNumericOptions(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		options  NumericOptions
+		query    string
+		expected bson.D
+	}{
+		"Default_Success": {
+			NumericOptions{}, `a==12;b==1.5`,
+			bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: int64(12)}},
+				bson.D{{Key: "b", Value: 1.5}},
+			}}},
+		},
+		"Int32Width_Success": {
+			NumericOptions{IntWidth: Int32Width}, `a=in=(1,-2)`,
+			bson.D{{Key: "a", Value: bson.D{bson.E{Key: "$in", Value: bson.A{int32(1), int32(-2)}}}}},
+		},
+		"AutoIntWidth_Success": {
+			NumericOptions{IntWidth: AutoIntWidth}, `a=in=(1,3000000000)`,
+			bson.D{{Key: "a", Value: bson.D{bson.E{Key: "$in", Value: bson.A{int32(1), int64(3000000000)}}}}},
+		},
+		"Decimal_Success": {
+			NumericOptions{Decimal: true}, `a=ge=12.50;b==3`,
+			bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: bson.D{{Key: "$gte", Value: mustDecimal(t, "12.50")}}}},
+				bson.D{{Key: "b", Value: int64(3)}},
+			}}},
+		},
+		"DecimalLiteral_Success": {
+			NumericOptions{}, `a==$dec(12.50);b=lt=$dec(-1E-2)`,
+			bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: mustDecimal(t, "12.50")}},
+				bson.D{{Key: "b", Value: bson.D{{Key: "$lt", Value: mustDecimal(t, "-0.01")}}}},
+			}}},
+		},
+		"Exponent_Success": {
+			NumericOptions{Exponent: true}, `a==1.5e3;b=gt=-2E-1`,
+			bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: 1500.0}},
+				bson.D{{Key: "b", Value: bson.D{{Key: "$gt", Value: -0.2}}}},
+			}}},
+		},
+		"Hex_Success": {
+			NumericOptions{Hex: true, IntWidth: AutoIntWidth}, `a==0x1F;b==-0XfF;c==017`,
+			bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: int32(31)}},
+				bson.D{{Key: "b", Value: int32(-255)}},
+				bson.D{{Key: "c", Value: int32(17)}},
+			}}},
+		},
+		"MixedTypes_Success": {
+			NumericOptions{MixedTypes: true}, `a==12;b!=1.5;c=in=(2.0,"x");d=ge=1`,
+			bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "a", Value: bson.D{bson.E{Key: "$in", Value: bson.A{
+					int32(12), int64(12), 12.0, mustDecimal(t, "12"),
+				}}}}},
+				bson.D{{Key: "b", Value: bson.D{bson.E{Key: "$nin", Value: bson.A{1.5, mustDecimal(t, "1.5")}}}}},
+				bson.D{{Key: "c", Value: bson.D{bson.E{Key: "$in", Value: bson.A{
+					int32(2), int64(2), 2.0, mustDecimal(t, "2"), "x",
+				}}}}},
+				bson.D{{Key: "d", Value: bson.D{{Key: "$gte", Value: int64(1)}}}},
+			}}},
+		},
+		"MixedTypesWithDecimal_Success": {
+			NumericOptions{MixedTypes: true}, `a==$dec(12.00)`,
+			bson.D{{Key: "a", Value: bson.D{bson.E{Key: "$in", Value: bson.A{
+				int32(12), int64(12), 12.0, mustDecimal(t, "12.00"),
+			}}}}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := NewParser(nil).SetNumericOptions(testCase.options).Parse(testCase.query)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, actual)
+		})
+	}
+
+	t.Run("CompatDialect_Success", func(t *testing.T) {
+		t.Parallel()
+
+		actual, err := NewParser(nil).SetDialect(CompatDialect).
+			SetNumericOptions(NumericOptions{Exponent: true, Hex: true}).Parse(`a==1e3;b==0x10;c==1e3x`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "a", Value: 1000.0}},
+			bson.D{{Key: "b", Value: int64(16)}},
+			bson.D{{Key: "c", Value: "1e3x"}},
+		}}}, actual)
+	})
+
+	t.Run("WithoutOptions_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for _, query := range []string{`a==1e3`, `a==0x1F`} {
+			_, err := NewParser(nil).Parse(query)
+			require.Error(t, err, query)
+		}
+	})
+
+	t.Run("Int32Overflow_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).SetNumericOptions(NumericOptions{IntWidth: Int32Width}).Parse(`a==3000000000`)
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidDecimal_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).Parse(`a==$dec(abc)`)
+		require.Error(t, err)
+	})
+}
+
+func mustDecimal(t *testing.T, value string) primitive.Decimal128 {
+	t.Helper()
+
+	decimal, err := primitive.ParseDecimal128(value)
+	require.NoError(t, err)
+
+	return decimal
+}
+
+func TestQueryParsingWithEscaping(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		query    string
+		expected bson.D
+	}{
+		"EscapedQuotes_Success": {
+			`a=="say \"hi\"";b=='it\'s'`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "a", Value: `say "hi"`}},
+				bson.D{bson.E{Key: "b", Value: "it's"}},
+			}}},
+		},
+		"QuotesWithinOtherQuotes_Success": {
+			`a=="it's";b=='say "hi"'`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "a", Value: "it's"}},
+				bson.D{bson.E{Key: "b", Value: `say "hi"`}},
+			}}},
+		},
+		"EscapedBackslash_Success": {
+			`a=="C:\\temp\\"`,
+			bson.D{bson.E{Key: "a", Value: `C:\temp\`}},
+		},
+		"PercentEncoded_Success": {
+			`a%3D%3D%22x%20%2B%20y%22`,
+			bson.D{bson.E{Key: "a", Value: "x + y"}},
+		},
+		"PercentEncodedBackslashEscape_Success": {
+			`a=="1%5C%2C2%5C%3B3"`,
+			bson.D{bson.E{Key: "a", Value: "1,2;3"}},
+		},
+		"DecodedOnlyOnce_Success": {
+			`a=="100%2525"`,
+			bson.D{bson.E{Key: "a", Value: "100%25"}},
+		},
+		"EscapedFieldName_Success": {
+			`a\=b\!c==1;\true==2`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "a=b!c", Value: int64(1)}},
+				bson.D{bson.E{Key: "true", Value: int64(2)}},
+			}}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteSuccessTest(t, NewParser(nil), testCase.query, testCase.expected)
+		})
+	}
+
+	t.Run("InvalidEncoding_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t, NewParser(nil), `a=="100%"`, errs.NewErrInvalidEncoding(7, `%"`))
+	})
+
+	t.Run("UnterminatedQuote_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).Parse(`a=="x\"`)
+		require.Error(t, err)
+	})
+}
+
+func TestQueryParsingWithCompatDialect(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		compat string
+		native string
+	}{
+		"UnquotedString_Success": {`name==John`, `name=="John"`},
+		"UnquotedTypedLiterals_Success": {
+			`a==true;b==NULL;c==-1.5;d==+2;e==abc`, `a==true;b==null;c==-1.5;d==2;e=="abc"`,
+		},
+		"QuotedString_Success":           {`name=="John Doe";nick=='JD'`, `name=="John Doe";nick=="JD"`},
+		"EscapedUnquoted_Success":        {`name==John\ Doe;a\<b==x`, `name=="John Doe";a<b=="x"`},
+		"KeywordComposites_Success":      {`a==1 and b==x OR c==y`, `(a==1;b=="x"),c=="y"`},
+		"AndBindsStronger_Success":       {`a==1;b==2,c==3`, `(a==1;b==2),c==3`},
+		"AndBindsStrongerRight_Success":  {`a==1,b==2;c==3,d==4`, `a==1,(b==2;c==3),d==4`},
+		"Context_Success":                {`(a==1,b==2);c==3`, `(a==1,b==2);c==3`},
+		"NotEqualAlias_Success":          {`a=ne=x`, `a!="x"`},
+		"NotInAlias_Success":             {`a=nin=(x,2)`, `a=out=("x",2)`},
+		"RangeAliases_Success":           {`a<1;b<=2;c>3;d>=4`, `a=lt=1;b=le=2;c=gt=3;d=ge=4`},
+		"DateLiteral_Success":            {`at=ge=$date(2024-01-01)`, `at=ge=$date(2024-01-01)`},
+		"UnquotedStringOperator_Success": {`name=sw=Jo;code=ew=123`, `name=sw="Jo";code=ew="123"`},
+		"List_Success":                   {`a=in=(x,y,"z z",1)`, `a=in=("x","y","z z",1)`},
+		"BoolOperator_Success":           {`a=exists=true;b=null=FALSE`, `a=exists=true;b=null=false`},
+		"Size_Success":                   {`tags=size=2`, `tags=size=2`},
+		"ElemMatch_Success":              {`items=em=(sku==x;qty>1,qty<0)`, `items=em=((sku=="x";qty=gt=1),qty=lt=0)`},
+		"Negation_Success":               {`!(a==1,b==2);c==3`, `!(a==1,b==2);c==3`},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expected, err := NewParser(nil).Parse(testCase.native)
+			require.NoError(t, err)
+
+			testutil.ExecuteSuccessTest(t, NewParser(nil).SetDialect(CompatDialect), testCase.compat, expected)
+		})
+	}
+
+	t.Run("UnquotedDate_Success", func(t *testing.T) {
 		t.Parallel()
 
 		testutil.ExecuteSuccessTest(t,
-			NewParser(
-				tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name", "age")),
-			`name=="steven",age=ge=18`,
-			bson.D{
-				bson.E{Key: "$or", Value: bson.A{
-					bson.D{
-						bson.E{Key: "name", Value: "steven"},
+			NewParser(nil).SetDialect(CompatDialect),
+			`at=gt=2024-01-01;name=le=m`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "at", Value: bson.D{bson.E{Key: "$gt", Value: "2024-01-01"}}}},
+				bson.D{bson.E{Key: "name", Value: bson.D{bson.E{Key: "$lte", Value: "m"}}}},
+			}}},
+		)
+	})
+
+	t.Run("UnquotedDateWithSmartParser_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+		require.NoError(t, err)
+
+		testutil.ExecuteSuccessTest(t,
+			parser.SetDialect(CompatDialect),
+			`created_at=gt=2024-01-01`,
+			bson.D{bson.E{Key: "created_at", Value: bson.D{bson.E{
+				Key: "$gt", Value: primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			}}}},
+		)
+	})
+
+	t.Run("PolicyOnlyChecksFieldNames_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "a")).SetDialect(CompatDialect),
+			`a==secret`,
+			bson.D{bson.E{Key: "a", Value: "secret"}},
+		)
+	})
+
+	t.Run("PolicyViolation_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t,
+			NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "a")).SetDialect(CompatDialect),
+			`a==1 or secret==1`,
+			errs.NewErrPolicyViolation("secret"),
+		)
+	})
+
+	t.Run("UnknownOperator_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t, NewParser(nil).SetDialect(CompatDialect), `a=foo=1`, errs.NewErrUnexpectedToken(1, "="))
+	})
+
+	t.Run("MissingComposite_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteFailedTest(t, NewParser(nil).SetDialect(CompatDialect), `a==1 b==2`,
+			errs.NewErrUnexpectedTokenType(5, UnquotedLiteralType.String(), ";/,"))
+	})
+
+	t.Run("NativeRejectsUnquoted_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).Parse(`name==John`)
+		require.Error(t, err)
+	})
+}
+
+func TestQueryParsingToAST(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithEmptyQuery_Success", func(t *testing.T) {
+		t.Parallel()
+
+		node, err := NewParser(nil).ParseAST("")
+		require.NoError(t, err)
+		require.Nil(t, node)
+	})
+
+	t.Run("WithComposites_Success", func(t *testing.T) {
+		t.Parallel()
+
+		node, err := NewParser(nil).ParseAST(`a==1;(b=sw="x",c=in=(true,2.5))`)
+		require.NoError(t, err)
+		require.Equal(t,
+			&AndNode{Position: 0, Children: []Node{
+				&ComparisonNode{
+					Field: "a", Operator: EqualOperator, Position: 0,
+					Argument: Literal{Value: int64(1), Kind: IntLiteralKind, Position: 3},
+				},
+				&GroupNode{Position: 5, Child: &OrNode{Position: 6, Children: []Node{
+					&ComparisonNode{
+						Field: "b", Operator: StartsWithOperator, Position: 6,
+						Argument: Literal{Value: "x", Kind: StringLiteralKind, Position: 11},
 					},
-					bson.D{
-						bson.E{Key: "age", Value: bson.D{
-							bson.E{Key: "$gte", Value: int64(18)},
+					&ComparisonNode{
+						Field: "c", Operator: InOperator, Position: 15,
+						Argument: Literal{Kind: ListLiteralKind, Position: 20, Value: []Literal{
+							{Value: true, Kind: BoolLiteralKind, Position: 21},
+							{Value: 2.5, Kind: FloatLiteralKind, Position: 26},
 						}},
 					},
-				}},
-			},
+				}}},
+			}},
+			node,
 		)
 	})
 
-	t.Run("WithDisallowedFieldNames_Fail", func(t *testing.T) {
+	t.Run("WithNegation_Success", func(t *testing.T) {
 		t.Parallel()
 
-		testutil.ExecuteFailedTest(t,
-			NewParser(
-				tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name", "age")),
-			`name=="steven",age=ge=18,gender="male"`,
-			errs.NewErrPolicyViolation("gender"),
+		node, err := NewParser(nil).ParseAST(`a==1;!(b==2)`)
+		require.NoError(t, err)
+		require.Equal(t,
+			&AndNode{Position: 0, Children: []Node{
+				&ComparisonNode{
+					Field: "a", Operator: EqualOperator, Position: 0,
+					Argument: Literal{Value: int64(1), Kind: IntLiteralKind, Position: 3},
+				},
+				&NotNode{Position: 5, Child: &GroupNode{Position: 6, Child: &ComparisonNode{
+					Field: "b", Operator: EqualOperator, Position: 7,
+					Argument: Literal{Value: int64(2), Kind: IntLiteralKind, Position: 10},
+				}}},
+			}},
+			node,
 		)
 	})
+
+	t.Run("WithMissingLiteral_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).ParseAST(`a==`)
+		require.Equal(t, errs.NewErrUnexpectedInputEnd("LITERAL"), err)
+	})
 }
 
 func TestInterpretation(t *testing.T) {
@@ -637,4 +1706,81 @@ func TestInterpretation(t *testing.T) {
 
 		testutil.FindCompare(t, collection, filter, nil, items[1], items[2], items[3])
 	})
+
+	t.Run("FilterByCaseInsensitiveLastName_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser := NewParser(nil)
+		filter, err := parser.Parse(`last_name=ieq="someone";first_name=contains="a"`)
+		require.NoError(t, err)
+
+		testutil.FindCompare(t, collection, filter, nil, items[2], items[3])
+	})
+}
+
+func TestQueryParsingConcurrently(t *testing.T) {
+	t.Parallel()
+
+	parser := NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "a", "b", "list", "list.c"))
+	queries := []string{`a==1;b=gt=2`, `list=em=(c=="x")`, `(a==1,b==2);list=em=(c=sw="y")`, `b=in=(1,2)`}
+
+	expected := make([]bson.D, len(queries))
+	for i, query := range queries {
+		filter, err := parser.Parse(query)
+		require.NoError(t, err, query)
+
+		expected[i] = filter
+	}
+
+	const goroutines = 16
+
+	results := make([][]bson.D, goroutines)
+	errors := make([]error, goroutines)
+
+	var group sync.WaitGroup
+
+	for i := 0; i < goroutines; i++ {
+		group.Add(1)
+
+		go func(i int) {
+			defer group.Done()
+
+			for j := 0; j < 100; j++ {
+				query := queries[(i+j)%len(queries)]
+
+				filter, err := parser.Parse(query)
+				if err != nil {
+					errors[i] = err
+
+					return
+				}
+
+				results[i] = append(results[i], filter)
+			}
+		}(i)
+	}
+
+	group.Wait()
+
+	for i := 0; i < goroutines; i++ {
+		require.NoError(t, errors[i])
+
+		for j, filter := range results[i] {
+			require.Equal(t, expected[(i+j)%len(queries)], filter)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	parser := NewParser(nil)
+	query := `(name=="john";age=ge=18),tags=em=(value=in=("a","b"));!deleted=exists=true`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.Parse(query); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
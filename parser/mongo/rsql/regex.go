@@ -0,0 +1,43 @@
+package rsql
+
+import (
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// likeWildcard is the wildcard of the like operator.
+const likeWildcard = "*"
+
+// Regex returns the regular expression for a string comparison operator.
+// The literal is always quoted, only the `*` wildcard of `=like=` matches
+// any sequence of characters. The second return value is false if the
+// operator is not a string comparison operator.
+func Regex(operator Operator, literal string) (primitive.Regex, bool) {
+	quoted := regexp.QuoteMeta(literal)
+
+	switch operator { //nolint:exhaustive
+	case StartsWithOperator:
+		return primitive.Regex{Pattern: "^" + quoted}, true
+	case EndsWithOperator:
+		return primitive.Regex{Pattern: quoted + "$"}, true
+	case ContainsOperator:
+		return primitive.Regex{Pattern: quoted}, true
+	case LikeOperator:
+		parts := strings.Split(literal, likeWildcard)
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+
+		return primitive.Regex{Pattern: "^" + strings.Join(parts, ".*") + "$"}, true
+	case CaseInsensitiveStartsWithOperator:
+		return primitive.Regex{Pattern: "^" + quoted, Options: "i"}, true
+	case CaseInsensitiveEndsWithOperator:
+		return primitive.Regex{Pattern: quoted + "$", Options: "i"}, true
+	case CaseInsensitiveEqualOperator:
+		return primitive.Regex{Pattern: "^" + quoted + "$", Options: "i"}, true
+	}
+
+	return primitive.Regex{}, false
+}
@@ -0,0 +1,34 @@
+package rsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRegex(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[Operator]primitive.Regex{
+		StartsWithOperator:                {Pattern: `^a\.b`},
+		EndsWithOperator:                  {Pattern: `a\.b$`},
+		ContainsOperator:                  {Pattern: `a\.b`},
+		CaseInsensitiveStartsWithOperator: {Pattern: `^a\.b`, Options: "i"},
+		CaseInsensitiveEndsWithOperator:   {Pattern: `a\.b$`, Options: "i"},
+		CaseInsensitiveEqualOperator:      {Pattern: `^a\.b$`, Options: "i"},
+	}
+
+	for operator, expected := range testCases {
+		actual, ok := Regex(operator, "a.b")
+		require.True(t, ok)
+		require.Equal(t, expected, actual, operator)
+	}
+
+	actual, ok := Regex(LikeOperator, "a*b.(c)*")
+	require.True(t, ok)
+	require.Equal(t, primitive.Regex{Pattern: `^a.*b\.\(c\).*$`}, actual)
+
+	_, ok = Regex(EqualOperator, "a")
+	require.False(t, ok)
+}
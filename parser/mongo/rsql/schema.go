@@ -0,0 +1,434 @@
+package rsql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// typeClass is the class of a field type that decides
+// which operators and literals can be used with the field.
+type typeClass byte
+
+const (
+	anyClass typeClass = iota
+	boolClass
+	stringClass
+	intClass
+	floatClass
+	oidClass
+	dateClass
+	decimalClass
+	structClass
+)
+
+//nolint:gochecknoglobals
+var (
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	timeType     = reflect.TypeOf(time.Time{})
+	dateTimeType = reflect.TypeOf(primitive.DateTime(0))
+	decimalType  = reflect.TypeOf(primitive.Decimal128{})
+)
+
+// newSchema creates a schema from given struct type.
+func newSchema(reference reflect.Type) (*schema, error) {
+	if reference == nil {
+		return nil, ErrNilReference
+	}
+
+	reference = indirectType(reference)
+	if classOf(reference) != structClass {
+		return nil, ErrInvalidReference
+	}
+
+	return &schema{reference: reference}, nil
+}
+
+// schema checks an abstract syntax tree against the `bson` tags of a reference type.
+type schema struct {
+	reference reflect.Type
+}
+
+// apply checks the fields and operators of given node
+// and coerces the literals to the types of the fields.
+func (s *schema) apply(node Node, prefix string) error {
+	switch node := node.(type) {
+	case *AndNode:
+		return s.applyAll(node.Children, prefix)
+	case *OrNode:
+		return s.applyAll(node.Children, prefix)
+	case *GroupNode:
+		return s.apply(node.Child, prefix)
+	case *NotNode:
+		return s.apply(node.Child, prefix)
+	case *ElemMatchNode:
+		field := prefix + node.Field
+		name := displayName(field, node.Alias)
+
+		fieldType, exists := s.fieldType(field)
+		if !exists {
+			return UnknownFieldError{field: name, position: node.Position}
+		}
+
+		if !isArrayType(fieldType) && classOf(fieldType) != anyClass {
+			return OperatorMismatchError{
+				field: name, operator: ElemMatchOperator, fieldType: fieldType.String(), position: node.Position,
+			}
+		}
+
+		return s.apply(node.Child, field+".")
+	case *ComparisonNode:
+		return s.comparison(node, prefix)
+	}
+
+	return nil
+}
+
+// applyAll applies the schema to all given nodes.
+func (s *schema) applyAll(nodes []Node, prefix string) error {
+	for _, node := range nodes {
+		if err := s.apply(node, prefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// comparison checks the field and operator of a comparison and coerces the argument.
+// Comparisons on arrays use the type of the elements like MongoDB does.
+func (s *schema) comparison(node *ComparisonNode, prefix string) error {
+	if node.Operator == TextOperator {
+		return nil
+	}
+
+	field := prefix + node.Field
+	name := displayName(field, node.Alias)
+
+	fieldType, exists := s.fieldType(field)
+	if !exists {
+		return UnknownFieldError{field: name, position: node.Position}
+	}
+
+	array := isArrayType(fieldType)
+
+	elementType := fieldType
+	if array {
+		elementType = indirectType(fieldType.Elem())
+	}
+
+	class := classOf(elementType)
+	mismatch := OperatorMismatchError{
+		field: name, operator: node.Operator, fieldType: fieldType.String(), position: node.Position,
+	}
+
+	switch node.Operator {
+	case EqualOperator, NotEqualOperator, InOperator, NotInOperator:
+	case GreaterThanOperator, GreaterThanOrEqualOperator, LessThanOperator, LessThanOrEqualOperator:
+		if class == boolClass || class == structClass {
+			return mismatch
+		}
+
+		// strings are only compared as dates or ObjectIDs
+		if node.Argument.Kind == StringLiteralKind && class != dateClass && class != oidClass {
+			return LiteralMismatchError{
+				field: name, kind: StringLiteralKind, fieldType: fieldType.String(), position: node.Argument.Position,
+			}
+		}
+	case StartsWithOperator, EndsWithOperator, LikeOperator, ContainsOperator,
+		CaseInsensitiveStartsWithOperator, CaseInsensitiveEndsWithOperator, CaseInsensitiveEqualOperator:
+		if class != stringClass && class != anyClass {
+			return mismatch
+		}
+	case NearOperator, WithinOperator, BoxOperator:
+		// GeoJSON points are documents and legacy coordinate pairs are arrays
+		if !array && class != structClass && class != anyClass {
+			return mismatch
+		}
+
+		return nil
+	case AllOperator, SizeOperator:
+		if !array && class != anyClass {
+			return mismatch
+		}
+
+		if node.Operator == SizeOperator {
+			return nil
+		}
+	default:
+		// the arguments of =exists=, =null= and custom operators don't depend on the field
+		return nil
+	}
+
+	return s.coerce(&node.Argument, name, elementType)
+}
+
+// displayName returns the name of a field as used in the query.
+func displayName(field, alias string) string {
+	if alias != "" {
+		return alias
+	}
+
+	return field
+}
+
+// coerce converts a literal into the type of a field.
+//
+//nolint:cyclop
+func (s *schema) coerce(literal *Literal, field string, fieldType reflect.Type) error {
+	mismatch := LiteralMismatchError{
+		field: field, kind: literal.Kind, fieldType: fieldType.String(), position: literal.Position,
+	}
+
+	switch literal.Kind { //nolint:exhaustive
+	case NullLiteralKind:
+		return nil
+	case ListLiteralKind:
+		items, _ := literal.Value.([]Literal)
+		for i := range items {
+			if err := s.coerce(&items[i], field, fieldType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	switch classOf(fieldType) {
+	case anyClass:
+		return nil
+	case boolClass:
+		if literal.Kind == BoolLiteralKind {
+			return nil
+		}
+	case stringClass:
+		if literal.Kind == StringLiteralKind {
+			return nil
+		}
+	case oidClass:
+		if literal.Kind == OidLiteralKind {
+			return nil
+		}
+
+		if value, ok := literal.Value.(string); ok {
+			if oid, err := primitive.ObjectIDFromHex(value); err == nil {
+				literal.Value, literal.Kind = oid, OidLiteralKind
+
+				return nil
+			}
+		}
+	case dateClass:
+		if literal.Kind == DateLiteralKind {
+			return nil
+		}
+
+		if value, ok := literal.Value.(string); ok {
+			date, err := parseDate(value)
+			if err != nil {
+				return fmt.Errorf("could not parse date '%s' of field '%s': %w", value, field, err)
+			}
+
+			literal.Value, literal.Kind = date, DateLiteralKind
+
+			return nil
+		}
+	case intClass:
+		if value, ok := toInt(literal.Value); ok {
+			return coerceInt(literal, value, fieldType, mismatch)
+		}
+	case floatClass:
+		if value, ok := toInt(literal.Value); ok {
+			literal.Value, literal.Kind = float64(value), FloatLiteralKind
+
+			return nil
+		}
+
+		if literal.Kind == FloatLiteralKind {
+			return nil
+		}
+	case decimalClass:
+		return coerceDecimal(literal, mismatch)
+	case structClass:
+	}
+
+	return mismatch
+}
+
+// coerceDecimal converts an integer or float into a `primitive.Decimal128`.
+func coerceDecimal(literal *Literal, mismatch error) error {
+	var number string
+
+	switch value := literal.Value.(type) {
+	case primitive.Decimal128:
+		return nil
+	case int32, int64:
+		number = fmt.Sprint(value)
+	case float64:
+		number = strconv.FormatFloat(value, 'f', -1, float64Size)
+	default:
+		return mismatch
+	}
+
+	value, err := primitive.ParseDecimal128(number)
+	if err != nil {
+		return mismatch
+	}
+
+	literal.Value, literal.Kind = value, DecimalLiteralKind
+
+	return nil
+}
+
+// coerceInt converts an integer into an `int32` for small integer types
+// and rejects values that overflow the type of the field.
+func coerceInt(literal *Literal, value int64, fieldType reflect.Type, mismatch error) error {
+	switch fieldType.Kind() { //nolint:exhaustive
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value < 0 || reflect.Zero(fieldType).OverflowUint(uint64(value)) {
+			return mismatch
+		}
+	default:
+		if reflect.Zero(fieldType).OverflowInt(value) {
+			return mismatch
+		}
+	}
+
+	switch fieldType.Kind() { //nolint:exhaustive
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		literal.Value = int32(value)
+	}
+
+	return nil
+}
+
+// fieldType returns the type of the field with given dotted path.
+// Paths into maps and interfaces can not be checked and are accepted.
+func (s *schema) fieldType(path string) (reflect.Type, bool) {
+	current := s.reference
+
+	for _, segment := range strings.Split(path, ".") {
+		if isArrayType(current) {
+			current = indirectType(current.Elem())
+
+			if _, err := strconv.Atoi(segment); err == nil {
+				continue
+			}
+		}
+
+		switch classOf(current) {
+		case anyClass:
+			return current, true
+		case structClass:
+			field, exists := bsonField(current, segment)
+			if !exists {
+				return nil, false
+			}
+
+			current = indirectType(field.Type)
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// isScalar checks if the field with given dotted path has
+// a known type that is not an array and contains no arrays.
+func (s *schema) isScalar(path string) bool {
+	current := s.reference
+
+	for _, segment := range strings.Split(path, ".") {
+		if classOf(current) != structClass {
+			return false
+		}
+
+		field, exists := bsonField(current, segment)
+		if !exists {
+			return false
+		}
+
+		current = indirectType(field.Type)
+		if isArrayType(current) {
+			return false
+		}
+	}
+
+	class := classOf(current)
+
+	return class != anyClass && class != structClass
+}
+
+// bsonField returns the field of a struct with given `bson` name.
+// Fields without name in the `bson` tag are ignored unless they are inlined.
+func bsonField(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("bson"), ",")
+		if tag[0] == name && name != "-" {
+			return field, true
+		}
+
+		if tag[0] == "" && len(tag) > 1 && tag[1] == "inline" && classOf(indirectType(field.Type)) == structClass {
+			if inlined, exists := bsonField(indirectType(field.Type), name); exists {
+				return inlined, true
+			}
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// classOf returns the class of given type.
+func classOf(valueType reflect.Type) typeClass {
+	switch valueType {
+	case objectIDType:
+		return oidClass
+	case timeType, dateTimeType:
+		return dateClass
+	case decimalType:
+		return decimalClass
+	}
+
+	switch valueType.Kind() { //nolint:exhaustive
+	case reflect.Bool:
+		return boolClass
+	case reflect.String:
+		return stringClass
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return intClass
+	case reflect.Float32, reflect.Float64:
+		return floatClass
+	case reflect.Struct:
+		return structClass
+	}
+
+	return anyClass
+}
+
+// isArrayType checks if given type is an array (excluding binary data).
+func isArrayType(valueType reflect.Type) bool {
+	if valueType.Kind() != reflect.Slice && valueType.Kind() != reflect.Array {
+		return false
+	}
+
+	return valueType.Elem().Kind() != reflect.Uint8
+}
+
+// indirectType returns the type a pointer points to.
+func indirectType(valueType reflect.Type) reflect.Type {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	return valueType
+}
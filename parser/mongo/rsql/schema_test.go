@@ -0,0 +1,211 @@
+//nolint:funlen
+package rsql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type schemaItem struct {
+	Sku string `bson:"sku"`
+	Qty int32  `bson:"qty"`
+}
+
+type schemaMeta struct {
+	Version int `bson:"version"`
+}
+
+type schemaDoc struct {
+	Meta       schemaMeta             `bson:",inline"`
+	ID         primitive.ObjectID     `bson:"_id"`
+	Name       string                 `bson:"name,omitempty"`
+	Age        int                    `bson:"age"`
+	Level      int8                   `bson:"level"`
+	Score      float64                `bson:"score"`
+	Price      primitive.Decimal128   `bson:"price"`
+	Active     bool                   `bson:"active"`
+	CreatedAt  time.Time              `bson:"created_at"`
+	DeletedAt  *time.Time             `bson:"deleted_at"`
+	Roles      []string               `bson:"roles"`
+	Items      []schemaItem           `bson:"items"`
+	Attributes map[string]interface{} `bson:"attributes"`
+	Secret     string                 `bson:"-"`
+	Untagged   string
+}
+
+func TestNewSmartParser(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewSmartParser(nil)
+	require.ErrorIs(t, err, ErrNilReference)
+
+	_, err = NewSmartParser(reflect.TypeOf(1))
+	require.ErrorIs(t, err, ErrInvalidReference)
+
+	_, err = NewSmartParser(reflect.TypeOf(&schemaDoc{}))
+	require.NoError(t, err)
+}
+
+func TestSmartParsing(t *testing.T) {
+	t.Parallel()
+
+	parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+	require.NoError(t, err)
+
+	t.Run("WithQuotedObjectID_Success", func(t *testing.T) {
+		t.Parallel()
+
+		oid, err := primitive.ObjectIDFromHex("01234567890abcdef1234567")
+		require.NoError(t, err)
+
+		filter, err := parser.Parse(`_id=="01234567890abcdef1234567"`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "_id", Value: oid}}, filter)
+
+		filter, err = parser.Parse(`_id=gt="01234567890abcdef1234567"`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "_id", Value: bson.D{bson.E{Key: "$gt", Value: oid}}}}, filter)
+	})
+
+	t.Run("WithIntegers_Success", func(t *testing.T) {
+		t.Parallel()
+
+		filter, err := parser.Parse(`age=gt=18;level==3;items.qty=in=(1,2)`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "$and", Value: bson.A{
+			bson.D{bson.E{Key: "age", Value: bson.D{bson.E{Key: "$gt", Value: int64(18)}}}},
+			bson.D{bson.E{Key: "level", Value: int32(3)}},
+			bson.D{bson.E{Key: "items.qty", Value: bson.D{bson.E{Key: "$in", Value: bson.A{int32(1), int32(2)}}}}},
+		}}}, filter)
+	})
+
+	t.Run("WithFloat_Success", func(t *testing.T) {
+		t.Parallel()
+
+		filter, err := parser.Parse(`score=ge=1`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "score", Value: bson.D{bson.E{Key: "$gte", Value: float64(1)}}}}, filter)
+	})
+
+	t.Run("WithDecimal_Success", func(t *testing.T) {
+		t.Parallel()
+
+		filter, err := parser.Parse(`price=ge=12.5;price=lt=$dec(20.00)`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "$and", Value: bson.A{
+			bson.D{bson.E{Key: "price", Value: bson.D{bson.E{Key: "$gte", Value: mustDecimal(t, "12.5")}}}},
+			bson.D{bson.E{Key: "price", Value: bson.D{bson.E{Key: "$lt", Value: mustDecimal(t, "20.00")}}}},
+		}}}, filter)
+	})
+
+	t.Run("WithQuotedDate_Success", func(t *testing.T) {
+		t.Parallel()
+
+		filter, err := parser.Parse(`created_at=lt="2024-01-01";deleted_at==null`)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "$and", Value: bson.A{
+			bson.D{bson.E{Key: "created_at", Value: bson.D{bson.E{
+				Key: "$lt", Value: primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			}}}},
+			bson.D{bson.E{Key: "deleted_at", Value: nil}},
+		}}}, filter)
+	})
+
+	t.Run("WithArraysAndDynamicFields_Success", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parser.Parse(
+			`roles=="admin";roles=size=2;items=em=(sku=="x";qty=gt=1);items.0.sku=="y";attributes.any.path==1;version==2`)
+		require.NoError(t, err)
+	})
+
+	t.Run("WithUnknownField_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]error{
+			`name=="a";unknown==1`:  UnknownFieldError{field: "unknown", position: 10},
+			`Secret=="a"`:           UnknownFieldError{field: "Secret", position: 0},
+			`Untagged=="a"`:         UnknownFieldError{field: "Untagged", position: 0},
+			`items=em=(price==1)`:   UnknownFieldError{field: "items.price", position: 10},
+			`name.first=="a"`:       UnknownFieldError{field: "name.first", position: 0},
+			`created_at.year==2024`: UnknownFieldError{field: "created_at.year", position: 0},
+		} {
+			_, err := parser.Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+
+	t.Run("WithOperatorMismatch_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]error{
+			`active=gt=1`: OperatorMismatchError{
+				field: "active", operator: GreaterThanOperator, fieldType: "bool", position: 0,
+			},
+			`age=sw="1"`: OperatorMismatchError{
+				field: "age", operator: StartsWithOperator, fieldType: "int", position: 0,
+			},
+			`name=size=1`: OperatorMismatchError{
+				field: "name", operator: SizeOperator, fieldType: "string", position: 0,
+			},
+			`name=em=(a==1)`: OperatorMismatchError{
+				field: "name", operator: ElemMatchOperator, fieldType: "string", position: 0,
+			},
+		} {
+			_, err := parser.Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+
+	t.Run("WithLiteralMismatch_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]error{
+			`age=="18"`: LiteralMismatchError{
+				field: "age", kind: StringLiteralKind, fieldType: "int", position: 5,
+			},
+			`age==1.5`: LiteralMismatchError{
+				field: "age", kind: FloatLiteralKind, fieldType: "int", position: 5,
+			},
+			`level==300`: LiteralMismatchError{
+				field: "level", kind: IntLiteralKind, fieldType: "int8", position: 7,
+			},
+			`_id=="xyz"`: LiteralMismatchError{
+				field: "_id", kind: StringLiteralKind, fieldType: "primitive.ObjectID", position: 5,
+			},
+			`roles=in=("a",1)`: LiteralMismatchError{
+				field: "roles", kind: IntLiteralKind, fieldType: "string", position: 14,
+			},
+			`name==true`: LiteralMismatchError{
+				field: "name", kind: BoolLiteralKind, fieldType: "string", position: 6,
+			},
+			`name=gt="m"`: LiteralMismatchError{
+				field: "name", kind: StringLiteralKind, fieldType: "string", position: 8,
+			},
+		} {
+			_, err := parser.Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+
+	t.Run("WithInvalidDate_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parser.Parse(`created_at=gt="yesterday"`)
+		require.ErrorIs(t, err, ErrInvalidDate)
+	})
+
+	t.Run("WithInvalidFieldName_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parser.Parse(`$where=="a"`)
+		require.Equal(t, errs.NewErrInvalidFieldName(0, "$where", tokenizer.OperatorSegmentReason), err)
+	})
+}
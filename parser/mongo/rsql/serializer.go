@@ -0,0 +1,227 @@
+package rsql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SerializeFilter converts a MongoDB filter created by the parser back into a query.
+func SerializeFilter(filter bson.D) (string, error) {
+	node, err := Decode(filter)
+	if err != nil {
+		return "", err
+	}
+
+	return Serialize(node)
+}
+
+// Serialize converts an abstract syntax tree into a canonical query.
+// Special characters of field names and strings are escaped with a backslash,
+// `%` is percent-encoded and contexts are only added where required.
+// A nil node results in an empty query.
+func Serialize(node Node) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+
+	return serialize(node, "")
+}
+
+// serialize converts a node into a query, aliases
+// of fields are relative to the alias prefix.
+func serialize(node Node, aliasPrefix string) (string, error) {
+	switch node := node.(type) {
+	case *AndNode:
+		return serializeComposite(node.Children, AndCompositeType.String(), aliasPrefix)
+	case *OrNode:
+		return serializeComposite(node.Children, OrCompositeType.String(), aliasPrefix)
+	case *GroupNode:
+		return serialize(node.Child, aliasPrefix)
+	case *NotNode:
+		child, err := serializeTerm(node.Child, aliasPrefix)
+		if err != nil {
+			return "", err
+		}
+
+		return NotType.String() + child, nil
+	case *ElemMatchNode:
+		field, err := serializeField(node.Field, node.Alias, aliasPrefix)
+		if err != nil {
+			return "", err
+		}
+
+		if node.Alias != "" {
+			aliasPrefix = node.Alias + "."
+		}
+
+		child, err := serialize(node.Child, aliasPrefix)
+		if err != nil {
+			return "", err
+		}
+
+		return field + string(ElemMatchOperator) + "(" + child + ")", nil
+	case *ComparisonNode:
+		return serializeComparison(node, aliasPrefix)
+	}
+
+	return "", fmt.Errorf("%w: node of type '%T'", ErrUnserializable, node)
+}
+
+// serializeComposite joins the children with given composite operator.
+func serializeComposite(children []Node, operator, aliasPrefix string) (string, error) {
+	terms := make([]string, 0, len(children))
+
+	for _, child := range children {
+		term, err := serializeTerm(child, aliasPrefix)
+		if err != nil {
+			return "", err
+		}
+
+		terms = append(terms, term)
+	}
+
+	return strings.Join(terms, operator), nil
+}
+
+// serializeTerm converts a node into a query
+// that is wrapped in a context if it is a composite.
+func serializeTerm(node Node, aliasPrefix string) (string, error) {
+	for {
+		group, ok := node.(*GroupNode)
+		if !ok {
+			break
+		}
+
+		node = group.Child
+	}
+
+	query, err := serialize(node, aliasPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	switch node.(type) {
+	case *AndNode, *OrNode:
+		return "(" + query + ")", nil
+	}
+
+	return query, nil
+}
+
+// serializeComparison converts a comparison into a query.
+func serializeComparison(node *ComparisonNode, aliasPrefix string) (string, error) {
+	field, err := serializeField(node.Field, node.Alias, aliasPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	argument, err := serializeLiteral(node.Argument)
+	if err != nil {
+		return "", err
+	}
+
+	// the pseudo-field of text searches is not escaped
+	if node.Operator == TextOperator {
+		field = TextSearchField
+	}
+
+	if node.Argument.Kind == ListLiteralKind {
+		argument = "(" + argument + ")"
+	}
+
+	return field + string(node.Operator) + argument, nil
+}
+
+// serializeField returns the encoded name of a field. If the field is mapped,
+// the alias is used since queries contain the names of the API.
+func serializeField(field, alias, aliasPrefix string) (string, error) {
+	if alias != "" {
+		field = strings.TrimPrefix(alias, aliasPrefix)
+	}
+
+	if field == "" {
+		return "", fmt.Errorf("%w: empty field", ErrUnserializable)
+	}
+
+	return escapeField(field), nil
+}
+
+// serializeLiteral converts a literal into a query.
+//
+//nolint:cyclop
+func serializeLiteral(literal Literal) (string, error) {
+	switch value := literal.Value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int32:
+		return strconv.FormatInt(int64(value), intBase), nil
+	case int64:
+		return strconv.FormatInt(value, intBase), nil
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return "", fmt.Errorf("%w: float '%f'", ErrUnserializable, value)
+		}
+
+		number := strconv.FormatFloat(value, 'f', -1, float64Size)
+		if !strings.Contains(number, ".") {
+			number += ".0"
+		}
+
+		return number, nil
+	case primitive.Decimal128:
+		return "$dec(" + value.String() + ")", nil
+	case primitive.ObjectID:
+		return "$oid(" + value.Hex() + ")", nil
+	case time.Time:
+		return "$date(" + value.UTC().Format(time.RFC3339Nano) + ")", nil
+	case string:
+		return serializeString(value), nil
+	case []Literal:
+		items := make([]string, 0, len(value))
+
+		for _, item := range value {
+			serialized, err := serializeLiteral(item)
+			if err != nil {
+				return "", err
+			}
+
+			items = append(items, serialized)
+		}
+
+		return strings.Join(items, OrCompositeType.String()), nil
+	}
+
+	return "", fmt.Errorf("%w: literal of kind '%s'", ErrUnserializable, literal.Kind)
+}
+
+// serializeString quotes and escapes a string.
+func serializeString(value string) string {
+	return `"` + tokenizer.Escape(value, `"`) + `"`
+}
+
+// escapeField escapes the operator characters of a field name and the first character
+// if the name would otherwise start with another token, e.g. `(` or `true`.
+func escapeField(field string) string {
+	escaped := tokenizer.Escape(field, "!=")
+
+	lower := strings.ToLower(escaped)
+	first, _ := utf8.DecodeRuneInString(escaped)
+
+	if !(unicode.IsLetter(first) || first == '_' || first == '\\') ||
+		strings.HasPrefix(lower, "true") || strings.HasPrefix(lower, "false") || strings.HasPrefix(lower, "null") {
+		return `\` + escaped
+	}
+
+	return escaped
+}
@@ -0,0 +1,180 @@
+package rsql
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+//nolint:funlen
+func TestSerializeFilter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Canonical_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]string{
+			`a==1`:                               `a==1`,
+			`a==1.50`:                            `a==1.5`,
+			`a==2.0`:                             `a==2.0`,
+			`a=="x" ; b!=true`:                   `a=="x";b!=true`,
+			`(a==1,b==2);c==3`:                   `(a==1,b==2);c==3`,
+			`a==1,(b==2;c==3)`:                   `a==1,(b==2;c==3)`,
+			`a=in=(1,"x",null)`:                  `a=in=(1,"x",null)`,
+			`a=out=(1,2)`:                        `a=out=(1,2)`,
+			`a=sw="x.y"`:                         `a=sw="x.y"`,
+			`a=like="x*y*"`:                      `a=like="x*y*"`,
+			`a=ieq="x"`:                          `a=ieq="x"`,
+			`a=null=false`:                       `a=null=false`,
+			`!a==1`:                              `!a==1`,
+			`!(a==1;b==2)`:                       `!(a==1;b==2)`,
+			`tags=em=(name=="x";size=gt=1)`:      `tags=em=(name=="x";size=gt=1)`,
+			`a=='say "hi"'`:                      `a=="say%20\"hi\""`,
+			`a=='it\'s'`:                         `a=="it's"`,
+			`a=="1 %5C%2C 2"`:                    `a=="1%20,%202"`,
+			`a=="100%25"`:                        `a=="100%25"`,
+			`\true==1`:                           `\true==1`,
+			`a\=b%5C%21c==1`:                     `a\=b\!c==1`,
+			`id==$oid(5ca9d6e4a8b1f8a6c0e4d1a2)`: `id==$oid(5ca9d6e4a8b1f8a6c0e4d1a2)`,
+			`at=ge=$date(2024-01-02)`:            `at=ge=$date(2024-01-02T00:00:00Z)`,
+			`loc=near=(13.4,52.52,1000)`:         `loc=near=(13.4,52.52,1000.0)`,
+			`loc=within=(1,2,6378.1)`:            `loc=within=(1.0,2.0,6378.1)`,
+		} {
+			filter, err := NewParser(nil).Parse(query)
+			require.NoError(t, err, query)
+
+			actual, err := SerializeFilter(filter)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, actual, query)
+		}
+	})
+
+	t.Run("RoundTrip_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for _, query := range []string{
+			`a==1;b=gt=2;c=ge=3;d=lt=4.5;e=le=-5`,
+			`a=sw="^x"`, `a=ew="x$"`, `a=contains="(x)"`, `a=like="*[x]*"`,
+			`a=isw="x"`, `a=iew="x*"`, `a=ieq="x?"`,
+			`a=exists=true,b=exists=false`, `a=null=true;b=null=false`,
+			`a=all=(1,2);a=size=2`,
+			`!(a==1,b==2);!c=sw="x";!d=in=(1)`,
+			`a==1;(b==2,(c==3;d==4)),e==5`,
+			`list=em=(!a==1;b=em=(c==2))`,
+			`a=="x, y; z=w $ %25"`, `a=="a %26 b %23 1 %2B 1"`,
+			`a=="say \"hi\" \\ 'x'"`,
+			`\(a==1;\ b==2;\1==3;\null==4`,
+			`at=lt=$date(2024-01-02T03:04:05.123Z)`,
+			`a==$dec(12.50);b=lt=$dec(-1E-2)`,
+			`loc=near=(13.4,52.52,1000);a==1`,
+			`loc=within=(-73.97,40.77,6378.1)`, `loc=within=(13.4,52.52,1000)`,
+			`loc=box=(0,0,100,90),loc=box=($dec(0.5),-1.25,1,1)`,
+		} {
+			filter, err := NewParser(nil).Parse(query)
+			require.NoError(t, err, query)
+
+			serialized, err := SerializeFilter(filter)
+			require.NoError(t, err, query)
+
+			reparsed, err := NewParser(nil).Parse(serialized)
+			require.NoError(t, err, serialized)
+			require.Equal(t, filter, reparsed, serialized)
+		}
+	})
+
+	t.Run("Empty_Success", func(t *testing.T) {
+		t.Parallel()
+
+		actual, err := SerializeFilter(bson.D{})
+		require.NoError(t, err)
+		require.Equal(t, "", actual)
+	})
+
+	t.Run("UnsupportedFilter_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for _, filter := range []bson.D{
+			{{Key: "$where", Value: "true"}},
+			{{Key: "a", Value: bson.D{{Key: "$mod", Value: bson.A{2, 0}}}}},
+			{{Key: "a", Value: bson.D{{Key: "b", Value: 1}}}},
+			{{Key: "a", Value: bson.A{1, 2}}},
+			{{Key: "a", Value: primitive.Regex{Pattern: "^a+"}}},
+			{{Key: "a", Value: primitive.Regex{Pattern: "x", Options: "i"}}},
+			{{Key: "$or", Value: bson.A{}}},
+			{{Key: "a", Value: bson.D{{Key: "$nearSphere", Value: bson.A{1, 2}}}}},
+			{{Key: "a", Value: bson.D{{Key: "$geoWithin", Value: bson.D{{Key: "$polygon", Value: bson.A{}}}}}}},
+			{{Key: "a", Value: bson.D{{Key: "$geoWithin", Value: bson.D{
+				{Key: "$box", Value: bson.A{bson.A{0, 0}, bson.A{1, 95}}},
+			}}}}},
+		} {
+			_, err := SerializeFilter(filter)
+			require.IsType(t, UnsupportedFilterError{}, err, filter)
+		}
+	})
+
+	t.Run("Unserializable_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for _, filter := range []bson.D{
+			{{Key: "a", Value: math.NaN()}},
+			{{Key: "a", Value: math.Inf(1)}},
+		} {
+			_, err := SerializeFilter(filter)
+			require.ErrorIs(t, err, ErrUnserializable, filter)
+		}
+	})
+}
+
+func TestSerialize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Nil_Success", func(t *testing.T) {
+		t.Parallel()
+
+		actual, err := Serialize(nil)
+		require.NoError(t, err)
+		require.Equal(t, "", actual)
+	})
+
+	t.Run("Group_Success", func(t *testing.T) {
+		t.Parallel()
+
+		ast, err := NewParser(nil).ParseAST(`((a==1));(b==2,c==$date(2024-01-02T03:04:05+01:00))`)
+		require.NoError(t, err)
+
+		actual, err := Serialize(ast)
+		require.NoError(t, err)
+		require.Equal(t, `a==1;(b==2,c==$date(2024-01-02T02:04:05Z))`, actual)
+	})
+
+	t.Run("FieldMapping_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser := NewParser(nil)
+		parser.SetFieldMapping(fieldmap.NewMapping(map[string]string{
+			"name":  "profile.full_name",
+			"items": "order.items",
+		}))
+
+		ast, err := parser.ParseAST(`name=="x";items=em=(sku=="y")`)
+		require.NoError(t, err)
+
+		actual, err := Serialize(ast)
+		require.NoError(t, err)
+		require.Equal(t, `name=="x";items=em=(sku=="y")`, actual)
+	})
+
+	t.Run("EmptyField_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Serialize(&ComparisonNode{
+			Operator: EqualOperator, Argument: Literal{Kind: DateLiteralKind, Value: time.Time{}},
+		})
+		require.ErrorIs(t, err, ErrUnserializable)
+	})
+}
@@ -0,0 +1,351 @@
+package rsql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Template is a query with named placeholders like `:userId` that is parsed once
+// and bound to typed values per call. Since values are bound to the abstract syntax
+// tree, they can't change the structure of the query. A template can be bound by
+// multiple goroutines.
+type Template struct {
+	parser     *Parser
+	node       Node
+	parameters []string
+}
+
+// Prepare parses a template with named placeholders like `owner==:userId;created_at=gt=:since`.
+// Placeholders can be used instead of literals and instead of the lists of `=in=`, `=out=`,
+// `=all=` and custom list operators. The field policies and the schema are checked when it is bound.
+func (p *Parser) Prepare(template string) (*Template, error) {
+	node, err := p.parse(template, p.templateSpecs)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]struct{}{}
+	collectParameters(node, names)
+
+	parameters := make([]string, 0, len(names))
+	for name := range names {
+		parameters = append(parameters, name)
+	}
+
+	sort.Strings(parameters)
+
+	return &Template{parser: p, node: node, parameters: parameters}, nil
+}
+
+// Parameters returns the sorted names of the placeholders.
+func (t *Template) Parameters() []string {
+	return append([]string{}, t.parameters...)
+}
+
+// Bind binds values to the placeholders and returns the MongoDB filter.
+func (t *Template) Bind(params map[string]interface{}) (bson.D, error) {
+	node, err := t.BindAST(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.parser.emit(node)
+}
+
+// BindAST binds values to the placeholders and returns the abstract syntax tree.
+// Each placeholder needs a value and values without placeholder are rejected.
+func (t *Template) BindAST(params map[string]interface{}) (Node, error) {
+	for _, name := range t.parameters {
+		if _, exists := params[name]; !exists {
+			return nil, MissingParameterError{name: name}
+		}
+	}
+
+	if len(params) > len(t.parameters) {
+		unknown := []string{}
+
+		for name := range params {
+			if index := sort.SearchStrings(t.parameters, name); index == len(t.parameters) ||
+				t.parameters[index] != name {
+				unknown = append(unknown, name)
+			}
+		}
+
+		sort.Strings(unknown)
+
+		return nil, UnknownParameterError{name: unknown[0]}
+	}
+
+	binder := binder{Parser: t.parser, params: params}
+
+	node, err := binder.node(t.node)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPlacement(node); err != nil {
+		return nil, err
+	}
+
+	if err := t.parser.validate(node); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// collectParameters collects the names of the placeholders of a node.
+func collectParameters(node Node, names map[string]struct{}) {
+	switch node := node.(type) {
+	case *AndNode:
+		for _, child := range node.Children {
+			collectParameters(child, names)
+		}
+	case *OrNode:
+		for _, child := range node.Children {
+			collectParameters(child, names)
+		}
+	case *GroupNode:
+		collectParameters(node.Child, names)
+	case *NotNode:
+		collectParameters(node.Child, names)
+	case *ElemMatchNode:
+		collectParameters(node.Child, names)
+	case *ComparisonNode:
+		if items, ok := node.Argument.Value.([]Literal); ok {
+			for _, item := range items {
+				if item.Kind == PlaceholderLiteralKind {
+					names[item.Value.(string)] = struct{}{} //nolint:forcetypeassert
+				}
+			}
+		}
+
+		if node.Argument.Kind == PlaceholderLiteralKind {
+			names[node.Argument.Value.(string)] = struct{}{} //nolint:forcetypeassert
+		}
+	}
+}
+
+// binder copies the abstract syntax tree of a template and replaces the placeholders.
+type binder struct {
+	*Parser
+	params map[string]interface{}
+}
+
+// node returns a copy of a node with bound values.
+func (b binder) node(node Node) (Node, error) {
+	switch node := node.(type) {
+	case *AndNode:
+		children, err := b.nodes(node.Children)
+
+		return &AndNode{Children: children, Position: node.Position}, err
+	case *OrNode:
+		children, err := b.nodes(node.Children)
+
+		return &OrNode{Children: children, Position: node.Position}, err
+	case *GroupNode:
+		child, err := b.node(node.Child)
+
+		return &GroupNode{Child: child, Position: node.Position}, err
+	case *NotNode:
+		child, err := b.node(node.Child)
+
+		return &NotNode{Child: child, Position: node.Position}, err
+	case *ElemMatchNode:
+		child, err := b.node(node.Child)
+		elemMatch := *node
+		elemMatch.Child = child
+
+		return &elemMatch, err
+	case *ComparisonNode:
+		argument, err := b.argument(node)
+		if err != nil {
+			return nil, err
+		}
+
+		comparison := *node
+		comparison.Argument = argument
+
+		return &comparison, checkGeo(&comparison)
+	}
+
+	return node, nil
+}
+
+// nodes returns copies of nodes with bound values.
+func (b binder) nodes(nodes []Node) ([]Node, error) {
+	copies := make([]Node, 0, len(nodes))
+
+	for _, node := range nodes {
+		copied, err := b.node(node)
+		if err != nil {
+			return nil, err
+		}
+
+		copies = append(copies, copied)
+	}
+
+	return copies, nil
+}
+
+// argument returns the argument of a comparison with bound values.
+func (b binder) argument(node *ComparisonNode) (Literal, error) {
+	argument := node.Argument
+
+	if argument.Kind == PlaceholderLiteralKind {
+		name := argument.Value.(string) //nolint:forcetypeassert
+
+		if b.takesList(node.Operator) {
+			return b.list(name, node.Operator, argument.Position)
+		}
+
+		return b.literal(name, node.Operator, b.params[name], argument.Position)
+	}
+
+	items, ok := argument.Value.([]Literal)
+	if !ok {
+		return argument, nil
+	}
+
+	bound := make([]Literal, 0, len(items))
+
+	for _, item := range items {
+		if item.Kind == PlaceholderLiteralKind {
+			name := item.Value.(string) //nolint:forcetypeassert
+
+			literal, err := b.literal(name, node.Operator, b.params[name], item.Position)
+			if err != nil {
+				return Literal{}, err
+			}
+
+			item = literal
+		}
+
+		bound = append(bound, item)
+	}
+
+	argument.Value = bound
+
+	return argument, nil
+}
+
+// takesList checks if a placeholder of an operator is bound to a list.
+func (b binder) takesList(operator Operator) bool {
+	switch operator { //nolint:exhaustive
+	case InOperator, NotInOperator, AllOperator:
+		return true
+	}
+
+	custom, exists := b.operators[operator]
+
+	return exists && custom.argKind == LiteralListArg
+}
+
+// list converts a slice or array into a list literal.
+func (b binder) list(name string, operator Operator, position int) (Literal, error) {
+	value := b.params[name]
+	reflected := reflect.ValueOf(value)
+
+	isList := value != nil && (reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Array)
+	if !isList || reflected.Len() == 0 {
+		return Literal{}, ParameterTypeError{
+			name: name, operator: operator, valueType: fmt.Sprintf("%T", value), position: position,
+		}
+	}
+
+	if err := check(MaxListLengthLimit, b.limits.MaxListLength, reflected.Len(), position); err != nil {
+		return Literal{}, err
+	}
+
+	items := make([]Literal, 0, reflected.Len())
+
+	for index := 0; index < reflected.Len(); index++ {
+		item, err := b.literal(name, operator, reflected.Index(index).Interface(), position)
+		if err != nil {
+			return Literal{}, err
+		}
+
+		items = append(items, item)
+	}
+
+	return Literal{Value: items, Kind: ListLiteralKind, Position: position}, nil
+}
+
+// literal converts a value into a literal and checks if it can be used with the operator.
+func (b binder) literal(name string, operator Operator, value interface{}, position int) (Literal, error) {
+	mismatch := ParameterTypeError{name: name, operator: operator, valueType: fmt.Sprintf("%T", value), position: position}
+
+	literal, err := builderLiteral(value)
+	if err != nil || !b.acceptsLiteral(operator, literal) {
+		return Literal{}, mismatch
+	}
+
+	literal.Position = position
+
+	switch literal.Kind { //nolint:exhaustive
+	case IntLiteralKind:
+		integer, _ := toInt(literal.Value)
+
+		literal.Value, _, err = intValue(integer, b.numeric.IntWidth)
+		if err != nil {
+			return Literal{}, err
+		}
+	case StringLiteralKind:
+		value, _ := literal.Value.(string)
+
+		if isStringOperator(operator) {
+			if err := check(MaxRegexLengthLimit, b.limits.MaxRegexLength, len(value), position); err != nil {
+				return Literal{}, err
+			}
+		}
+	}
+
+	return literal, nil
+}
+
+// acceptsLiteral checks if a bound literal can be used with an operator like a parsed one.
+func (b binder) acceptsLiteral(operator Operator, literal Literal) bool {
+	switch operator { //nolint:exhaustive
+	case ExistsOperator, NullOperator:
+		return literal.Kind == BoolLiteralKind
+	case TextOperator:
+		return literal.Kind == StringLiteralKind
+	case NearOperator, WithinOperator, BoxOperator:
+		_, ok := geoNumber(literal.Value)
+
+		return ok
+	case SizeOperator:
+		size, ok := toInt(literal.Value)
+
+		return literal.Kind == IntLiteralKind && ok && size >= 0
+	case GreaterThanOperator, GreaterThanOrEqualOperator, LessThanOperator, LessThanOrEqualOperator:
+		switch literal.Kind { //nolint:exhaustive
+		case IntLiteralKind, FloatLiteralKind, DecimalLiteralKind, DateLiteralKind:
+			return true
+		case StringLiteralKind:
+			return b.acceptsStringRange()
+		}
+
+		return false
+	}
+
+	if isStringOperator(operator) {
+		return literal.Kind == StringLiteralKind
+	}
+
+	return true
+}
+
+// isStringOperator checks if an operator only takes strings like `=sw=`.
+func isStringOperator(operator Operator) bool {
+	switch operator { //nolint:exhaustive
+	case StartsWithOperator, EndsWithOperator, LikeOperator, ContainsOperator,
+		CaseInsensitiveStartsWithOperator, CaseInsensitiveEndsWithOperator, CaseInsensitiveEqualOperator:
+		return true
+	}
+
+	return false
+}
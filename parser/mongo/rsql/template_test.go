@@ -0,0 +1,178 @@
+package rsql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+//nolint:funlen
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Bind_Success", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).Prepare(`owner==:userId;created_at=gt=:since;status=in=:statuses`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"since", "statuses", "userId"}, template.Parameters())
+
+		actual, err := template.Bind(map[string]interface{}{
+			"userId": "steven", "since": since, "statuses": []string{"active", "archived"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "$and", Value: bson.A{
+			bson.D{bson.E{Key: "owner", Value: "steven"}},
+			bson.D{bson.E{Key: "created_at", Value: bson.D{
+				bson.E{Key: "$gt", Value: primitive.NewDateTimeFromTime(since)},
+			}}},
+			bson.D{bson.E{Key: "status", Value: bson.D{bson.E{Key: "$in", Value: bson.A{"active", "archived"}}}}},
+		}}}, actual)
+	})
+
+	t.Run("BindMultipleTimes_Success", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).Prepare(`a=in=(:x,2);list=em=(b=size=:size);c=exists=:exists`)
+		require.NoError(t, err)
+
+		for x, expected := range map[int]bson.D{
+			1: {bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "a", Value: bson.D{bson.E{Key: "$in", Value: bson.A{int64(1), int64(2)}}}}},
+				bson.D{bson.E{Key: "list", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+					bson.E{Key: "b", Value: bson.D{bson.E{Key: "$size", Value: int64(3)}}},
+				}}}}},
+				bson.D{bson.E{Key: "c", Value: bson.D{bson.E{Key: "$exists", Value: true}}}},
+			}}},
+			5: {bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "a", Value: bson.D{bson.E{Key: "$in", Value: bson.A{int64(5), int64(2)}}}}},
+				bson.D{bson.E{Key: "list", Value: bson.D{bson.E{Key: "$elemMatch", Value: bson.D{
+					bson.E{Key: "b", Value: bson.D{bson.E{Key: "$size", Value: int64(3)}}},
+				}}}}},
+				bson.D{bson.E{Key: "c", Value: bson.D{bson.E{Key: "$exists", Value: true}}}},
+			}}},
+		} {
+			actual, err := template.Bind(map[string]interface{}{"x": x, "size": uint8(3), "exists": true})
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("BindInjection_Success", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).Prepare(`owner==:userId`)
+		require.NoError(t, err)
+
+		actual, err := template.Bind(map[string]interface{}{"userId": `x",owner!="x`})
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "owner", Value: `x",owner!="x`}}, actual)
+	})
+
+	t.Run("BindWithSmartParser_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+		require.NoError(t, err)
+
+		template, err := parser.Prepare(`level==:level`)
+		require.NoError(t, err)
+
+		actual, err := template.Bind(map[string]interface{}{"level": 3})
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "level", Value: int32(3)}}, actual)
+
+		_, err = template.Bind(map[string]interface{}{"level": "high"})
+		require.Equal(t, LiteralMismatchError{field: "level", kind: StringLiteralKind, fieldType: "int8", position: 7}, err)
+	})
+
+	t.Run("BindWithFieldPolicies_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).
+			SetFieldPolicies(FieldPolicies{"status": {Values: []interface{}{"active"}}}).
+			Prepare(`status==:status`)
+		require.NoError(t, err)
+
+		_, err = template.Bind(map[string]interface{}{"status": "deleted"})
+		require.Equal(t, errs.NewErrFieldPolicyViolation(0, "status", "==", "value 'deleted' is not allowed"), err)
+	})
+
+	t.Run("BindWithLimits_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).SetLimits(Limits{MaxListLength: 2}).Prepare(`a=in=:values`)
+		require.NoError(t, err)
+
+		_, err = template.Bind(map[string]interface{}{"values": []int{1, 2, 3}})
+		require.Equal(t, errs.NewErrLimitExceeded(5, MaxListLengthLimit, 2), err)
+	})
+
+	t.Run("BindParameters_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).Prepare(`owner==:userId;created_at=gt=:since`)
+		require.NoError(t, err)
+
+		_, err = template.Bind(map[string]interface{}{"userId": "steven"})
+		require.Equal(t, MissingParameterError{name: "since"}, err)
+
+		_, err = template.Bind(map[string]interface{}{"userId": "steven", "since": since, "limit": 1})
+		require.Equal(t, UnknownParameterError{name: "limit"}, err)
+	})
+
+	t.Run("BindStringRangeWithCompatDialect_Success", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewParser(nil).SetDialect(CompatDialect).Prepare(`a=lt=:value`)
+		require.NoError(t, err)
+
+		actual, err := template.Bind(map[string]interface{}{"value": "m"})
+		require.NoError(t, err)
+		require.Equal(t, bson.D{bson.E{Key: "a", Value: bson.D{bson.E{Key: "$lt", Value: "m"}}}}, actual)
+	})
+
+	t.Run("BindType_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for template, expected := range map[string]ParameterTypeError{
+			`a=gt=:value`:     {name: "value", operator: GreaterThanOperator, valueType: "bool", position: 5},
+			`a=lt=:value`:     {name: "value", operator: LessThanOperator, valueType: "string", position: 5},
+			`a=exists=:value`: {name: "value", operator: ExistsOperator, valueType: "string", position: 9},
+			`a=size=:value`:   {name: "value", operator: SizeOperator, valueType: "int", position: 7},
+			`a=sw=:value`:     {name: "value", operator: StartsWithOperator, valueType: "int", position: 5},
+			`a=in=:value`:     {name: "value", operator: InOperator, valueType: "string", position: 5},
+			`a==:value`:       {name: "value", operator: EqualOperator, valueType: "[]string", position: 3},
+		} {
+			prepared, err := NewParser(nil).Prepare(template)
+			require.NoError(t, err, template)
+
+			values := map[string]map[string]interface{}{
+				`a=gt=:value`:     {"value": true},
+				`a=lt=:value`:     {"value": "m"},
+				`a=exists=:value`: {"value": "yes"},
+				`a=size=:value`:   {"value": -1},
+				`a=sw=:value`:     {"value": 1},
+				`a=in=:value`:     {"value": "active"},
+				`a==:value`:       {"value": []string{"active"}},
+			}[template]
+
+			_, err = prepared.Bind(values)
+			require.Equal(t, expected, err, template)
+		}
+	})
+
+	t.Run("PlaceholderInQuery_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).Parse(`owner==:userId`)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,164 @@
+package rsql
+
+import (
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TextSearchOptions configures the `$text` clauses of text searches.
+// The zero value uses the defaults of the text index.
+type TextSearchOptions struct {
+	// Language is the language of the search e.g. `"de"`.
+	Language string
+	// CaseSensitive enables case sensitive searches.
+	CaseSensitive bool
+	// DiacriticSensitive enables diacritic sensitive searches.
+	DiacriticSensitive bool
+}
+
+/*
+ * <text_search>
+ *   | "*" "=text=" <quoted_string_literal>
+ * .
+ */
+func (p *state) textSearch(field *tokenizer.Token) (*ComparisonNode, error) {
+	_, err := p.eat(TextOperatorType)
+	if err != nil {
+		return nil, err
+	}
+
+	if field.Value != TextSearchField {
+		return nil, TextSearchError{reason: "must be used on the pseudo-field '*'", position: field.Position}
+	}
+
+	literal, err := p.stringLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComparisonNode{
+		Field: TextSearchField, Operator: TextOperator, Argument: *literal, Position: field.Position,
+	}, nil
+}
+
+// checkTextSearch checks that a query has at most one text search and that it
+// is not nested in OR, NOT or `=em=` since MongoDB rejects such queries.
+func checkTextSearch(node Node) error {
+	found := false
+
+	if position, reason := findTopLevel(node, TextOperator, false, &found); reason != "" {
+		return TextSearchError{reason: reason, position: position}
+	}
+
+	return nil
+}
+
+// findTopLevel finds comparisons with an operator that can only be used once and only
+// within AND, nested is true within OR, NOT and `=em=`. The reason is empty if it is valid.
+func findTopLevel(node Node, operator Operator, nested bool, found *bool) (int, string) {
+	switch node := node.(type) {
+	case *AndNode:
+		for _, child := range node.Children {
+			if position, reason := findTopLevel(child, operator, nested, found); reason != "" {
+				return position, reason
+			}
+		}
+	case *OrNode:
+		for _, child := range node.Children {
+			if position, reason := findTopLevel(child, operator, true, found); reason != "" {
+				return position, reason
+			}
+		}
+	case *GroupNode:
+		return findTopLevel(node.Child, operator, nested, found)
+	case *NotNode:
+		return findTopLevel(node.Child, operator, true, found)
+	case *ElemMatchNode:
+		return findTopLevel(node.Child, operator, true, found)
+	case *ComparisonNode:
+		if node.Operator != operator {
+			return 0, ""
+		}
+
+		if nested {
+			return node.Position, "can not be nested in OR, NOT or =em="
+		}
+
+		if *found {
+			return node.Position, "can only be used once"
+		}
+
+		*found = true
+	}
+
+	return 0, ""
+}
+
+// findComparison returns the first comparison with given operator or nil.
+func findComparison(node Node, operator Operator) *ComparisonNode {
+	var children []Node
+
+	switch node := node.(type) {
+	case *AndNode:
+		children = node.Children
+	case *OrNode:
+		children = node.Children
+	case *GroupNode:
+		children = []Node{node.Child}
+	case *NotNode:
+		children = []Node{node.Child}
+	case *ElemMatchNode:
+		children = []Node{node.Child}
+	case *ComparisonNode:
+		if node.Operator == operator {
+			return node
+		}
+	}
+
+	for _, child := range children {
+		if found := findComparison(child, operator); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// textSearchClause converts the search of a text search into a `$text` clause.
+func (e *MongoEmitter) textSearchClause(search interface{}) bson.E {
+	clause := bson.D{bson.E{Key: "$search", Value: search}}
+
+	if e.textSearch.Language != "" {
+		clause = append(clause, bson.E{Key: "$language", Value: e.textSearch.Language})
+	}
+
+	if e.textSearch.CaseSensitive {
+		clause = append(clause, bson.E{Key: "$caseSensitive", Value: true})
+	}
+
+	if e.textSearch.DiacriticSensitive {
+		clause = append(clause, bson.E{Key: "$diacriticSensitive", Value: true})
+	}
+
+	return bson.E{Key: "$text", Value: clause}
+}
+
+// decodeTextSearch converts a `$text` clause into a text search.
+// The options of the clause are dropped since they are configured by the parser.
+func decodeTextSearch(element bson.E) (Node, error) {
+	clause, ok := element.Value.(bson.D)
+	if !ok {
+		return nil, UnsupportedFilterError{key: element.Key}
+	}
+
+	for _, option := range clause {
+		if search, ok := option.Value.(string); ok && option.Key == "$search" {
+			return &ComparisonNode{
+				Field: TextSearchField, Operator: TextOperator,
+				Argument: Literal{Kind: StringLiteralKind, Value: search},
+			}, nil
+		}
+	}
+
+	return nil, UnsupportedFilterError{key: element.Key}
+}
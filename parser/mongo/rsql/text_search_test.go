@@ -0,0 +1,101 @@
+package rsql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/StevenCyb/goapiutils/parser/mongo/test_util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+//nolint:funlen
+func TestQueryParsingWithTextSearch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TextSearch_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`*=text="coffee shop"`,
+			bson.D{bson.E{Key: "$text", Value: bson.D{bson.E{Key: "$search", Value: "coffee shop"}}}},
+		)
+	})
+
+	t.Run("WithFilters_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil),
+			`(status=="open";*=text="coffee");rating=gt=3`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "$and", Value: bson.A{
+					bson.D{bson.E{Key: "status", Value: "open"}},
+					bson.D{bson.E{Key: "$text", Value: bson.D{bson.E{Key: "$search", Value: "coffee"}}}},
+				}}},
+				bson.D{bson.E{Key: "rating", Value: bson.D{bson.E{Key: "$gt", Value: int64(3)}}}},
+			}}},
+		)
+	})
+
+	t.Run("WithOptions_Success", func(t *testing.T) {
+		t.Parallel()
+
+		testutil.ExecuteSuccessTest(t,
+			NewParser(nil).SetTextSearchOptions(TextSearchOptions{Language: "de", CaseSensitive: true}),
+			`*=text="kaffee"`,
+			bson.D{bson.E{Key: "$text", Value: bson.D{
+				bson.E{Key: "$search", Value: "kaffee"},
+				bson.E{Key: "$language", Value: "de"},
+				bson.E{Key: "$caseSensitive", Value: true},
+			}}},
+		)
+	})
+
+	t.Run("WithSmartParser_Success", func(t *testing.T) {
+		t.Parallel()
+
+		parser, err := NewSmartParser(reflect.TypeOf(schemaDoc{}))
+		require.NoError(t, err)
+
+		testutil.ExecuteSuccessTest(t,
+			parser,
+			`*=text="coffee";level==1`,
+			bson.D{bson.E{Key: "$and", Value: bson.A{
+				bson.D{bson.E{Key: "$text", Value: bson.D{bson.E{Key: "$search", Value: "coffee"}}}},
+				bson.D{bson.E{Key: "level", Value: int32(1)}},
+			}}},
+		)
+	})
+
+	t.Run("Serialization_Success", func(t *testing.T) {
+		t.Parallel()
+
+		query, err := TextSearch("coffee").And(Field("age").Gt(1)).Build()
+		require.NoError(t, err)
+		require.Equal(t, `*=text="coffee";age=gt=1`, query)
+
+		filter, err := NewParser(nil).Parse(query)
+		require.NoError(t, err)
+
+		serialized, err := SerializeFilter(filter)
+		require.NoError(t, err)
+		require.Equal(t, query, serialized)
+	})
+
+	t.Run("TextSearch_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]error{
+			`name=text="coffee"`:    TextSearchError{reason: "must be used on the pseudo-field '*'", position: 0},
+			`*=text="a";*=text="b"`: TextSearchError{reason: "can only be used once", position: 11},
+			`a==1,*=text="a"`:       TextSearchError{reason: "can not be nested in OR, NOT or =em=", position: 5},
+			`!(*=text="a")`:         TextSearchError{reason: "can not be nested in OR, NOT or =em=", position: 2},
+			`list=em=(*=text="a")`:  TextSearchError{reason: "can not be nested in OR, NOT or =em=", position: 9},
+		} {
+			_, err := NewParser(nil).Parse(query)
+			require.Equal(t, expected, err, query)
+		}
+	})
+}
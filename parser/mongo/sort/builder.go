@@ -0,0 +1,86 @@
+package sort
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+)
+
+// Asc starts a sort query with an ascending field.
+func Asc(field string) *Builder {
+	return (&Builder{}).Asc(field)
+}
+
+// Desc starts a sort query with a descending field.
+func Desc(field string) *Builder {
+	return (&Builder{}).Desc(field)
+}
+
+// Builder creates sort queries that are accepted by the parser.
+type Builder struct {
+	statements []string
+	err        error
+}
+
+// Asc sorts by given field in ascending order.
+func (b *Builder) Asc(field string) *Builder {
+	return b.add(field, "asc")
+}
+
+// Desc sorts by given field in descending order.
+func (b *Builder) Desc(field string) *Builder {
+	return b.add(field, "desc")
+}
+
+// add appends a sort statement with escaped field name.
+func (b *Builder) add(field, order string) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	if err := tokenizer.ValidateFieldName(field, 0); err != nil {
+		b.err = err
+
+		return b
+	}
+
+	escaped := tokenizer.Escape(field, "=,")
+
+	// escape the first character if the name would otherwise start with a sort condition,
+	// the field is checked since a leading space is percent-encoded
+	first, _ := utf8.DecodeRuneInString(field)
+	if unicode.IsSpace(first) || strings.HasPrefix(field, "asc") || strings.HasPrefix(field, "desc") ||
+		strings.HasPrefix(field, "1") || strings.HasPrefix(field, "-1") {
+		escaped = `\` + escaped
+	}
+
+	b.statements = append(b.statements, escaped+"="+order)
+
+	return b
+}
+
+// Build returns the sort query. The query is
+// checked with a parser to guarantee that it is accepted.
+func (b *Builder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	query := strings.Join(b.statements, ",")
+
+	if _, err := NewParser(nil).Parse(query); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnserializable, err.Error())
+	}
+
+	return query, nil
+}
+
+// String returns the sort query or an empty string if it is invalid.
+func (b *Builder) String() string {
+	query, _ := b.Build()
+
+	return query
+}
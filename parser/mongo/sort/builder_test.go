@@ -0,0 +1,66 @@
+package sort
+
+import (
+	"testing"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Build_Success", func(t *testing.T) {
+		t.Parallel()
+
+		query, err := Desc("created_at").Asc("last name").Asc("a,b").Build()
+		require.NoError(t, err)
+		require.Equal(t, `created_at=desc,last%20name=asc,a\,b=asc`, query)
+
+		actual, err := NewParser(nil).Parse(query)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{
+			bson.E{Key: "created_at", Value: -1},
+			bson.E{Key: "last name", Value: 1},
+			bson.E{Key: "a,b", Value: 1},
+		}, actual)
+	})
+
+	t.Run("String_Success", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, "name=asc", Asc("name").String())
+		require.Equal(t, "", Asc("$where").String())
+	})
+
+	t.Run("InvalidFieldName_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Asc("name").Desc("$where").Build()
+		require.Equal(t, errs.NewErrInvalidFieldName(0, "$where", tokenizer.OperatorSegmentReason), err)
+	})
+
+	t.Run("EscapedFieldName_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for field, expected := range map[string]string{
+			"a=b":       `a\=b=asc`,
+			"a%20b":     `a%2520b=asc`,
+			`a\b`:       `a\\b=asc`,
+			"ascending": `\ascending=asc`,
+			"1st":       `\1st=asc`,
+			" a":        `\%20a=asc`,
+			"a&b#c+d":   `a%26b%23c%2Bd=asc`,
+		} {
+			query, err := Asc(field).Build()
+			require.NoError(t, err, field)
+			require.Equal(t, expected, query)
+
+			actual, err := NewParser(nil).Parse(query)
+			require.NoError(t, err, query)
+			require.Equal(t, bson.D{bson.E{Key: field, Value: 1}}, actual)
+		}
+	})
+}
@@ -0,0 +1,6 @@
+package sort
+
+import "errors"
+
+// ErrUnserializable indicates that a builder can not create a valid sort query.
+var ErrUnserializable = errors.New("can not be serialized")
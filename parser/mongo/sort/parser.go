@@ -1,9 +1,8 @@
 package sort
 
 import (
-	"strings"
-
 	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
 	"github.com/StevenCyb/goapiutils/parser/tokenizer"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -17,14 +16,15 @@ const (
 	FieldNameType     tokenizer.Type = "FIELD_NAME"
 )
 
-// specialEncode is the map for encoding
-// a list of special characters.
+// specs of the tokenizer that are compiled once and shared by all parsers.
 //
 //nolint:gochecknoglobals
-var specialEncode = map[string]string{
-	`,`: "%5C%2C",
-	`=`: "%5C%3D",
-	` `: "%20",
+var specs = []*tokenizer.Spec{
+	tokenizer.NewSpec(`^\s+`, SkipType),
+	tokenizer.NewSpec(`^,`, AndType),
+	tokenizer.NewSpec(`^(=)`, SetType),
+	tokenizer.NewSpec(`^(asc|desc|1|-1)`, SortConditionType),
+	tokenizer.NewSpec(`^(?:[^=\\]|\\.)*`, FieldNameType),
 }
 
 // NewParser creates a new parser.
@@ -34,15 +34,40 @@ func NewParser(policy *tokenizer.Policy) *Parser {
 	}
 }
 
-// Parser provides the logic to parse rsql statements.
+// Parser provides the logic to parse sort statements. The state of a parse
+// is kept per call, so a configured parser can be used by multiple goroutines.
 type Parser struct {
+	policy           *tokenizer.Policy
+	mapping          *fieldmap.Mapping
+	unsafeFieldNames bool
+}
+
+// SetFieldMapping sets a mapping from the field names used in queries to storage paths.
+// Fields without mapping are rejected, the policy is checked with the unmapped names.
+func (p *Parser) SetFieldMapping(mapping *fieldmap.Mapping) *Parser {
+	p.mapping = mapping
+
+	return p
+}
+
+// AllowUnsafeFieldNames disables the field name validation that rejects
+// names with `$` prefixed or empty segments and control characters.
+// Only use this for trusted queries.
+func (p *Parser) AllowUnsafeFieldNames() *Parser {
+	p.unsafeFieldNames = true
+
+	return p
+}
+
+// state is the state of a single parse.
+type state struct {
+	*Parser
 	tokenizer *tokenizer.Tokenizer
 	lookahead *tokenizer.Token
-	policy    *tokenizer.Policy
 }
 
 // eat return a token with expected type.
-func (p *Parser) eat(tokenType tokenizer.Type) (*tokenizer.Token, error) {
+func (p *state) eat(tokenType tokenizer.Type) (*tokenizer.Token, error) {
 	token := p.lookahead
 
 	if token == nil {
@@ -71,29 +96,29 @@ func (p *Parser) Parse(query string) (bson.D, error) {
 		return bson.D{}, nil
 	}
 
-	for dec, enc := range specialEncode {
-		query = strings.ReplaceAll(query, enc, dec)
+	query, err = tokenizer.DecodeQuery(query)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
 	}
 
-	p.tokenizer = tokenizer.NewTokenizer(
-		query,
-		SkipType, FieldNameType,
-		[]*tokenizer.Spec{
-			tokenizer.NewSpec(`^\s+`, SkipType),
-			tokenizer.NewSpec(`^,`, AndType),
-			tokenizer.NewSpec(`^(=)`, SetType),
-			tokenizer.NewSpec(`^(asc|desc|1|-1)`, SortConditionType),
-			tokenizer.NewSpec(`^[^=]*`, FieldNameType),
-		},
-		p.policy,
-	)
+	// The policy is checked by sortStatement() since field names can be escaped.
 
-	p.lookahead, err = p.tokenizer.GetNextToken()
+	state := &state{
+		Parser: p,
+		tokenizer: tokenizer.NewTokenizer(
+			query,
+			SkipType, FieldNameType,
+			specs,
+			nil,
+		),
+	}
+
+	state.lookahead, err = state.tokenizer.GetNextToken()
 	if err != nil {
 		return nil, err //nolint:wrapcheck
 	}
 
-	return p.expression()
+	return state.expression()
 }
 
 /*
@@ -102,7 +127,7 @@ func (p *Parser) Parse(query string) (bson.D, error) {
  *   | <sort_statement> "," <sort_statement>
  * .
  */
-func (p *Parser) expression() ([]bson.E, error) {
+func (p *state) expression() ([]bson.E, error) {
 	sortStatements := []bson.E{}
 
 	if p.lookahead == nil {
@@ -138,12 +163,30 @@ func (p *Parser) expression() ([]bson.E, error) {
  *   : <key> "=" <sort_condition>
  * .
  */
-func (p *Parser) sortStatement() (*bson.E, error) {
+func (p *state) sortStatement() (*bson.E, error) {
 	keyToken, err := p.eat(FieldNameType)
 	if err != nil {
 		return nil, err
 	}
 
+	key := tokenizer.Unescape(keyToken.Value)
+	if p.policy != nil && !p.policy.Allow(key) {
+		return nil, errs.NewErrPolicyViolation(key)
+	}
+
+	if !p.unsafeFieldNames {
+		if err := tokenizer.ValidateFieldName(key, keyToken.Position); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	if p.mapping != nil {
+		var exists bool
+		if key, exists = p.mapping.Map(key); !exists {
+			return nil, errs.NewErrUnmappedField(keyToken.Position, tokenizer.Unescape(keyToken.Value))
+		}
+	}
+
 	_, err = p.eat(SetType)
 	if err != nil {
 		return nil, err
@@ -161,5 +204,5 @@ func (p *Parser) sortStatement() (*bson.E, error) {
 		sort = -1
 	}
 
-	return &bson.E{Key: keyToken.Value, Value: sort}, nil
+	return &bson.E{Key: key, Value: sort}, nil
 }
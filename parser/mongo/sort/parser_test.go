@@ -3,9 +3,11 @@ package sort
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/StevenCyb/goapiutils/parser/fieldmap"
 	testutil "github.com/StevenCyb/goapiutils/parser/mongo/test_util"
 	"github.com/StevenCyb/goapiutils/parser/tokenizer"
 	"github.com/stretchr/testify/require"
@@ -117,6 +119,113 @@ func TestParsing(t *testing.T) {
 			)
 		})
 	})
+
+	t.Run("WithFieldMapping", func(t *testing.T) {
+		t.Parallel()
+
+		mapping := fieldmap.NewMapping(map[string]string{
+			"createdAt": "meta.created_at",
+			"author":    "meta.author",
+		})
+
+		t.Run("WithMappedFieldNames_Success", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteSuccessTest(t,
+				NewParser(nil).SetFieldMapping(mapping),
+				"createdAt=desc,author.name=asc",
+				bson.D{bson.E{Key: "meta.created_at", Value: -1}, bson.E{Key: "meta.author.name", Value: 1}},
+			)
+		})
+
+		t.Run("WithUnmappedFieldName_Fail", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteFailedTest(t,
+				NewParser(nil).SetFieldMapping(mapping),
+				"createdAt=desc,meta.created_at=asc",
+				errs.NewErrUnmappedField(15, "meta.created_at"),
+			)
+		})
+
+		t.Run("WithPolicyOnUnmappedNames_Fail", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteFailedTest(t,
+				NewParser(tokenizer.NewPolicy(tokenizer.BlacklistPolicy, "author")).SetFieldMapping(mapping),
+				"createdAt=desc,author=asc",
+				errs.NewErrPolicyViolation("author"),
+			)
+		})
+	})
+
+	t.Run("WithFieldNameValidation", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("WithOperatorFieldName_Fail", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteFailedTest(t,
+				NewParser(nil),
+				"a=asc,$natural=desc",
+				errs.NewErrInvalidFieldName(6, "$natural", tokenizer.OperatorSegmentReason),
+			)
+		})
+
+		t.Run("WithEmptySegment_Fail", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteFailedTest(t,
+				NewParser(nil),
+				"a..b=asc",
+				errs.NewErrInvalidFieldName(0, "a..b", tokenizer.EmptySegmentReason),
+			)
+		})
+
+		t.Run("WithUnsafeFieldNames_Success", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteSuccessTest(t,
+				NewParser(nil).AllowUnsafeFieldNames(),
+				"$natural=desc",
+				bson.D{bson.E{Key: "$natural", Value: -1}},
+			)
+		})
+	})
+
+	t.Run("WithEscaping", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("WithEscapedFieldNames_Success", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteSuccessTest(t,
+				NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "a,b", "asc")),
+				`a\,b=asc,\asc=desc`,
+				bson.D{bson.E{Key: "a,b", Value: 1}, bson.E{Key: "asc", Value: -1}},
+			)
+		})
+
+		t.Run("WithPercentEncoding_Success", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteSuccessTest(t,
+				NewParser(nil),
+				"a%5C%2Cb%5C%3Dc=asc,d%2520e=desc",
+				bson.D{bson.E{Key: "a,b=c", Value: 1}, bson.E{Key: "d%20e", Value: -1}},
+			)
+		})
+
+		t.Run("WithInvalidEncoding_Fail", func(t *testing.T) {
+			t.Parallel()
+
+			testutil.ExecuteFailedTest(t,
+				NewParser(nil),
+				"a%zz=asc",
+				errs.NewErrInvalidEncoding(1, "%zz"),
+			)
+		})
+	})
 }
 
 func TestInterpretation(t *testing.T) {
@@ -167,3 +276,68 @@ func TestInterpretation(t *testing.T) {
 		testutil.FindCompare(t, collection, nil, sort, items[2], items[1], items[0], items[3])
 	})
 }
+
+func TestQueryParsingConcurrently(t *testing.T) {
+	t.Parallel()
+
+	parser := NewParser(nil)
+	queries := []string{`a=asc`, `a=desc,b=1`, `c\=d=-1`}
+
+	expected := make([]bson.D, len(queries))
+	for i, query := range queries {
+		sort, err := parser.Parse(query)
+		require.NoError(t, err, query)
+
+		expected[i] = sort
+	}
+
+	const goroutines = 16
+
+	results := make([][]bson.D, goroutines)
+	errors := make([]error, goroutines)
+
+	var group sync.WaitGroup
+
+	for i := 0; i < goroutines; i++ {
+		group.Add(1)
+
+		go func(i int) {
+			defer group.Done()
+
+			for j := 0; j < 100; j++ {
+				sort, err := parser.Parse(queries[(i+j)%len(queries)])
+				if err != nil {
+					errors[i] = err
+
+					return
+				}
+
+				results[i] = append(results[i], sort)
+			}
+		}(i)
+	}
+
+	group.Wait()
+
+	for i := 0; i < goroutines; i++ {
+		require.NoError(t, errors[i])
+
+		for j, sort := range results[i] {
+			require.Equal(t, expected[(i+j)%len(queries)], sort)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	parser := NewParser(nil)
+	query := `first_name=asc,last_name=desc,age=-1`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.Parse(query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package rsql
+
+import (
+	"fmt"
+)
+
+// UnsupportedOperatorError indicate that an operator can not be evaluated.
+type UnsupportedOperatorError struct {
+	operator string
+	position int
+}
+
+func (u UnsupportedOperatorError) Error() string {
+	return fmt.Sprintf("operator '%s' at position '%d' is not supported", u.operator, u.position)
+}
+
+// UnsupportedTypeError indicate that a value can not be evaluated.
+type UnsupportedTypeError struct {
+	kind string
+}
+
+func (u UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("value of kind '%s' is not supported, must be map or struct", u.kind)
+}
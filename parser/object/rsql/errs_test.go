@@ -0,0 +1,21 @@
+package rsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsupportedOperatorError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "operator '=x=' at position '3' is not supported",
+		UnsupportedOperatorError{operator: "=x=", position: 3}.Error())
+}
+
+func TestUnsupportedTypeError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "value of kind 'int' is not supported, must be map or struct",
+		UnsupportedTypeError{kind: "int"}.Error())
+}
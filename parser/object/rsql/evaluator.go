@@ -0,0 +1,453 @@
+package rsql
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	mongorsql "github.com/StevenCyb/goapiutils/parser/mongo/rsql"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Predicate reports whether a given value matches a query.
+type Predicate func(value interface{}) (bool, error)
+
+// matcher reports whether a resolved value matches.
+type matcher func(value reflect.Value) bool
+
+// Compile converts a given node into a predicate.
+// A nil node results in a predicate that matches everything.
+func Compile(node mongorsql.Node) (Predicate, error) {
+	if node == nil {
+		return func(value interface{}) (bool, error) {
+			return true, nil
+		}, nil
+	}
+
+	match, err := compile(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value interface{}) (bool, error) {
+		root := indirect(reflect.ValueOf(value))
+		if root.Kind() != reflect.Map && root.Kind() != reflect.Struct {
+			return false, UnsupportedTypeError{kind: root.Kind().String()}
+		}
+
+		return match(root), nil
+	}, nil
+}
+
+// compile converts a node into a matcher.
+func compile(node mongorsql.Node) (matcher, error) {
+	switch node := node.(type) {
+	case *mongorsql.AndNode:
+		return compileComposite(node.Children, true)
+	case *mongorsql.OrNode:
+		return compileComposite(node.Children, false)
+	case *mongorsql.GroupNode:
+		return compile(node.Child)
+	case *mongorsql.NotNode:
+		match, err := compile(node.Child)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(value reflect.Value) bool {
+			return !match(value)
+		}, nil
+	case *mongorsql.ComparisonNode:
+		return compileComparison(node)
+	case *mongorsql.ElemMatchNode:
+		return compileElemMatch(node)
+	}
+
+	return nil, errs.NewErrUnexpectedInput(node)
+}
+
+// compileComposite combines the children with a logical AND or OR.
+func compileComposite(children []mongorsql.Node, and bool) (matcher, error) {
+	matchers := make([]matcher, 0, len(children))
+
+	for _, child := range children {
+		match, err := compile(child)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, match)
+	}
+
+	return func(value reflect.Value) bool {
+		for _, match := range matchers {
+			if match(value) != and {
+				return !and
+			}
+		}
+
+		return and
+	}, nil
+}
+
+// compileElemMatch converts an element match into a matcher.
+func compileElemMatch(node *mongorsql.ElemMatchNode) (matcher, error) {
+	path := strings.Split(node.Field, ".")
+
+	match, err := compile(node.Child)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value reflect.Value) bool {
+		for _, resolved := range resolve(value, path) {
+			if containsMatch(resolved, match) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// compileComparison converts a comparison into a matcher.
+func compileComparison(node *mongorsql.ComparisonNode) (matcher, error) {
+	var (
+		path    = strings.Split(node.Field, ".")
+		literal = plain(node.Argument)
+		test    func(value reflect.Value) bool
+		negate  bool
+	)
+
+	switch node.Operator {
+	case mongorsql.ExistsOperator:
+		return func(value reflect.Value) bool {
+			return (len(resolve(value, path)) > 0) == literal
+		}, nil
+	case mongorsql.NullOperator:
+		return func(value reflect.Value) bool {
+			for _, resolved := range resolve(value, path) {
+				if !resolved.IsValid() {
+					return literal == true
+				}
+			}
+
+			return literal == false
+		}, nil
+	case mongorsql.EqualOperator, mongorsql.NotEqualOperator:
+		test = func(value reflect.Value) bool {
+			return equal(value, literal) || containsMatch(value, func(item reflect.Value) bool {
+				return equal(item, literal)
+			})
+		}
+		negate = node.Operator == mongorsql.NotEqualOperator
+	case mongorsql.GreaterThanOperator, mongorsql.GreaterThanOrEqualOperator,
+		mongorsql.LessThanOperator, mongorsql.LessThanOrEqualOperator:
+		test = ordered(node.Operator, literal)
+	case mongorsql.StartsWithOperator, mongorsql.EndsWithOperator, mongorsql.LikeOperator,
+		mongorsql.ContainsOperator, mongorsql.CaseInsensitiveStartsWithOperator,
+		mongorsql.CaseInsensitiveEndsWithOperator, mongorsql.CaseInsensitiveEqualOperator:
+		expression, err := wildcard(node.Operator, literal)
+		if err != nil {
+			return nil, err
+		}
+
+		test = func(value reflect.Value) bool {
+			return matchString(value, expression) || containsMatch(value, func(item reflect.Value) bool {
+				return matchString(item, expression)
+			})
+		}
+	case mongorsql.InOperator, mongorsql.NotInOperator:
+		items, _ := literal.([]interface{})
+		test = func(value reflect.Value) bool {
+			for _, item := range items {
+				if equal(value, item) || containsMatch(value, func(element reflect.Value) bool {
+					return equal(element, item)
+				}) {
+					return true
+				}
+			}
+
+			return false
+		}
+		negate = node.Operator == mongorsql.NotInOperator
+	case mongorsql.AllOperator:
+		items, _ := literal.([]interface{})
+		test = func(value reflect.Value) bool {
+			for _, item := range items {
+				if !equal(value, item) && !containsMatch(value, func(element reflect.Value) bool {
+					return equal(element, item)
+				}) {
+					return false
+				}
+			}
+
+			return len(items) > 0
+		}
+	case mongorsql.SizeOperator:
+		size, _ := literal.(int64)
+		test = func(value reflect.Value) bool {
+			return isList(value) && int64(value.Len()) == size
+		}
+	default:
+		return nil, UnsupportedOperatorError{operator: string(node.Operator), position: node.Position}
+	}
+
+	return func(value reflect.Value) bool {
+		resolved := resolve(value, path)
+		if len(resolved) == 0 {
+			// like MongoDB, a missing field is treated as null
+			resolved = []reflect.Value{{}}
+		}
+
+		for _, resolved := range resolved {
+			if test(resolved) {
+				return !negate
+			}
+		}
+
+		return negate
+	}, nil
+}
+
+// ordered returns a test for range comparisons.
+func ordered(operator mongorsql.Operator, literal interface{}) func(value reflect.Value) bool {
+	accept := map[mongorsql.Operator]func(result int) bool{
+		mongorsql.GreaterThanOperator:        func(result int) bool { return result > 0 },
+		mongorsql.GreaterThanOrEqualOperator: func(result int) bool { return result >= 0 },
+		mongorsql.LessThanOperator:           func(result int) bool { return result < 0 },
+		mongorsql.LessThanOrEqualOperator:    func(result int) bool { return result <= 0 },
+	}[operator]
+
+	test := func(value reflect.Value) bool {
+		result, comparable := compare(value, literal)
+
+		return comparable && accept(result)
+	}
+
+	return func(value reflect.Value) bool {
+		return test(value) || containsMatch(value, test)
+	}
+}
+
+// wildcard creates the expression for string comparisons
+// the same way as the MongoDB filter is created.
+func wildcard(operator mongorsql.Operator, literal interface{}) (*regexp.Regexp, error) {
+	regex, _ := mongorsql.Regex(operator, fmt.Sprintf("%v", literal))
+
+	pattern := regex.Pattern
+	if regex.Options != "" {
+		pattern = "(?" + regex.Options + ")" + pattern
+	}
+
+	expression, err := regexp.Compile(pattern)
+
+	return expression, errors.Wrap(err, "failed to create wildcard expression")
+}
+
+// plain converts a literal into a plain Go value.
+// Numbers are normalized to `int64` and `float64`.
+func plain(literal mongorsql.Literal) interface{} {
+	items, ok := literal.Value.([]mongorsql.Literal)
+	if !ok {
+		return normalize(literal.Value)
+	}
+
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		values = append(values, plain(item))
+	}
+
+	return values
+}
+
+// normalize converts `int32` and `primitive.Decimal128` numbers
+// of the numeric options into `int64` and `float64`.
+func normalize(value interface{}) interface{} {
+	switch number := value.(type) {
+	case int32:
+		return int64(number)
+	case primitive.Decimal128:
+		if float, ok := decimalToFloat(number); ok {
+			return float
+		}
+	}
+
+	return value
+}
+
+// decimalToFloat converts a decimal into a float.
+func decimalToFloat(decimal primitive.Decimal128) (float64, bool) {
+	float, err := strconv.ParseFloat(decimal.String(), 64) //nolint:gomnd
+
+	return float, err == nil
+}
+
+// containsMatch reports whether any element of an array matches.
+func containsMatch(value reflect.Value, test func(item reflect.Value) bool) bool {
+	if !isList(value) {
+		return false
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		if test(indirect(value.Index(i))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchString reports whether a string value matches the expression.
+func matchString(value reflect.Value, expression *regexp.Regexp) bool {
+	return value.Kind() == reflect.String && expression.MatchString(value.String())
+}
+
+// equal reports whether a value equals a literal.
+func equal(value reflect.Value, literal interface{}) bool {
+	if literal == nil || !value.IsValid() {
+		return literal == nil && !value.IsValid()
+	}
+
+	if items, ok := literal.([]interface{}); ok {
+		if !isList(value) || value.Len() != len(items) {
+			return false
+		}
+
+		for i, item := range items {
+			if !equal(indirect(value.Index(i)), item) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if oid, ok := literal.(primitive.ObjectID); ok {
+		actual, ok := value.Interface().(primitive.ObjectID)
+
+		return ok && actual == oid
+	}
+
+	if flag, ok := literal.(bool); ok {
+		return value.Kind() == reflect.Bool && value.Bool() == flag
+	}
+
+	result, comparable := compare(value, literal)
+
+	return comparable && result == 0
+}
+
+// compare compares a value with a numeric or string literal.
+func compare(value reflect.Value, literal interface{}) (int, bool) {
+	switch literal := literal.(type) {
+	case string:
+		if value.Kind() != reflect.String {
+			return 0, false
+		}
+
+		return strings.Compare(value.String(), literal), true
+	case int64:
+		if isInt(value) {
+			return compareInt(value.Int(), literal), true
+		}
+
+		if number, ok := toFloat(value); ok {
+			return compareFloat(number, float64(literal)), true
+		}
+	case float64:
+		if number, ok := toFloat(value); ok {
+			return compareFloat(number, literal), true
+		}
+	case time.Time:
+		if date, ok := toTime(value); ok {
+			return compareInt(date.UnixNano(), literal.UnixNano()), true
+		}
+	}
+
+	return 0, false
+}
+
+// toTime converts a `time.Time` or `primitive.DateTime` value into a time.
+func toTime(value reflect.Value) (time.Time, bool) {
+	if !value.IsValid() || !value.CanInterface() {
+		return time.Time{}, false
+	}
+
+	switch date := value.Interface().(type) {
+	case time.Time:
+		return date, true
+	case primitive.DateTime:
+		return date.Time(), true
+	}
+
+	return time.Time{}, false
+}
+
+// isList reports whether a value is an array (excluding binary data).
+func isList(value reflect.Value) bool {
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return false
+	}
+
+	return value.Type().Elem().Kind() != reflect.Uint8
+}
+
+// isInt reports whether a value is a signed integer.
+func isInt(value reflect.Value) bool {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+// toFloat converts a numeric value to float64.
+func toFloat(value reflect.Value) (float64, bool) {
+	if value.IsValid() && value.CanInterface() {
+		if decimal, ok := value.Interface().(primitive.Decimal128); ok {
+			return decimalToFloat(decimal)
+		}
+	}
+
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+
+	return 0, false
+}
+
+// compareInt compares two integers.
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// compareFloat compares two floats.
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,58 @@
+package rsql
+
+import (
+	mongorsql "github.com/StevenCyb/goapiutils/parser/mongo/rsql"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+)
+
+// NewParser creates a new parser.
+func NewParser(policy *tokenizer.Policy) *Parser {
+	return &Parser{
+		parser: mongorsql.NewParser(policy),
+	}
+}
+
+// Parser provides the logic to parse rsql
+// statements into predicates for Go values.
+type Parser struct {
+	parser *mongorsql.Parser
+}
+
+// SetQueryDialect sets the syntax of the queries, the default is `mongorsql.NativeDialect`.
+func (p *Parser) SetQueryDialect(dialect mongorsql.Dialect) *Parser {
+	p.parser.SetDialect(dialect)
+
+	return p
+}
+
+// SetFieldPolicies sets policies that restrict the operators and values of fields.
+func (p *Parser) SetFieldPolicies(policies mongorsql.FieldPolicies) *Parser {
+	p.parser.SetFieldPolicies(policies)
+
+	return p
+}
+
+// SetNumericOptions sets the options of numeric literals.
+func (p *Parser) SetNumericOptions(options mongorsql.NumericOptions) *Parser {
+	p.parser.SetNumericOptions(options)
+
+	return p
+}
+
+// SetLimits sets limits that restrict the complexity of queries.
+func (p *Parser) SetLimits(limits mongorsql.Limits) *Parser {
+	p.parser.SetLimits(limits)
+
+	return p
+}
+
+// Parse a given query into a predicate.
+// An empty query results in a predicate that matches everything.
+func (p *Parser) Parse(query string) (Predicate, error) {
+	node, err := p.parser.ParseAST(query)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return Compile(node)
+}
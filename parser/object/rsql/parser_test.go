@@ -0,0 +1,312 @@
+//nolint:funlen
+package rsql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	mongorsql "github.com/StevenCyb/goapiutils/parser/mongo/rsql"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type address struct {
+	City string `json:"city"`
+	Zip  int32  `bson:"zip"`
+}
+
+type item struct {
+	Sku string `bson:"sku"`
+	Qty int    `bson:"qty"`
+}
+
+type dummyDoc struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	FirstName string             `bson:"first_name"`
+	Roles     []string           `json:"roles"`
+	Items     []item             `bson:"items"`
+	Address   *address           `bson:"address"`
+	Score     float64
+	Active    bool   `bson:"active"`
+	Secret    string `bson:"-"`
+}
+
+func executeMatchTest(t *testing.T, query string, value interface{}, expected bool) {
+	t.Helper()
+
+	predicate, err := NewParser(nil).Parse(query)
+	require.NoError(t, err)
+
+	actual, err := predicate(value)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual, query)
+}
+
+func TestPredicateWithEmptyQuery_Success(t *testing.T) {
+	t.Parallel()
+
+	executeMatchTest(t, "", map[string]interface{}{}, true)
+}
+
+func TestPredicateWithMap(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{
+		"name":  "steven",
+		"age":   int32(30),
+		"pi":    3.14,
+		"roles": []interface{}{"dev", "admin"},
+		"meta": map[string]interface{}{
+			"level": "senior",
+		},
+		"deleted_at": nil,
+		"created_at": time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		"updated_at": primitive.NewDateTimeFromTime(time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+	}
+
+	testCases := map[string]bool{
+		`name=="steven"`:                  true,
+		`name=="max"`:                     false,
+		`name!="max"`:                     true,
+		`missing!="max"`:                  true,
+		`missing=="max"`:                  false,
+		`age==30`:                         true,
+		`age==30.0`:                       true,
+		`age=gt=29`:                       true,
+		`age=ge=31`:                       false,
+		`pi=lt=3.15`:                      true,
+		`pi=le=3`:                         false,
+		`name=gt=1`:                       false,
+		`!name=="steven"`:                 false,
+		`!(name=="steven";age==1)`:        true,
+		`!missing==1`:                     true,
+		`created_at=gt=$date(2024-01-01)`: true,
+		`created_at=lt=$date(2024-01-01)`: false,
+		`created_at==$date(2024-01-10)`:   true,
+		`updated_at=ge=$date(2024-01-20T00:00:00Z)`: true,
+		`name=gt=$date(2024-01-01)`:                 false,
+		`missing=gt=$date(2024-01-01)`:              false,
+		`!missing=lt=$date(2024-01-01)`:             true,
+		`roles=="admin"`:                            true,
+		`roles!="admin"`:                            false,
+		`roles==("dev","admin")`:                    true,
+		`roles==("admin","dev")`:                    false,
+		`roles=in=("x","dev")`:                      true,
+		`roles=out=("x","dev")`:                     false,
+		`missing=out=("x","dev")`:                   true,
+		`name=sw="ste"`:                             true,
+		`name=ew="ven"`:                             true,
+		`name=ew="ste"`:                             false,
+		`meta.level=="senior"`:                      true,
+		`meta.level=="senior";age==31`:              false,
+		`meta.level=="junior",age==30`:              true,
+		`(name=="max",age==30);pi==3.14`:            true,
+	}
+
+	for query, expected := range testCases {
+		executeMatchTest(t, query, value, expected)
+	}
+}
+
+func TestPredicateWithStruct(t *testing.T) {
+	t.Parallel()
+
+	oid, err := primitive.ObjectIDFromHex("01234567890abcdef1234567")
+	require.NoError(t, err)
+
+	value := dummyDoc{
+		ID:        oid,
+		FirstName: "Tina",
+		Roles:     []string{"dev"},
+		Items:     []item{{Sku: "a", Qty: 1}, {Sku: "b", Qty: 5}},
+		Address:   &address{City: "Berlin", Zip: 10115},
+		Score:     0.5,
+		Active:    true,
+		Secret:    "hidden",
+	}
+
+	testCases := map[string]bool{
+		`_id==$oid(01234567890abcdef1234567)`: true,
+		`_id!=$oid(01234567890abcdef1234567)`: false,
+		`first_name=="Tina"`:                  true,
+		`FirstName=="Tina"`:                   false,
+		`roles=="dev"`:                        true,
+		`items.sku=="b"`:                      true,
+		`items.qty=gt=4`:                      true,
+		`items.qty=gt=5`:                      false,
+		`items.0.sku=="a"`:                    true,
+		`items.1.sku=="a"`:                    false,
+		`address.city=="Berlin"`:              true,
+		`address.zip==10115`:                  true,
+		`score=lt=1`:                          true,
+		`Score=lt=1`:                          false,
+		`active==true`:                        true,
+		`active==false`:                       false,
+		`Secret=="hidden"`:                    false,
+		`secret=="hidden"`:                    false,
+		`items=em=(sku=="b";qty=gt=2)`:        true,
+		`items=em=(sku=="a";qty=gt=2)`:        false,
+		`items=em=(sku=="a",qty==5)`:          true,
+		`items=size=2`:                        true,
+		`roles=size=2`:                        false,
+		`roles=all=("dev")`:                   true,
+		`roles=all=("dev","admin")`:           false,
+		`first_name=all=("Tina")`:             true,
+	}
+
+	for query, expected := range testCases {
+		executeMatchTest(t, query, value, expected)
+		executeMatchTest(t, query, &value, expected)
+	}
+}
+
+func TestPredicateWithNumericOptions(t *testing.T) {
+	t.Parallel()
+
+	price, err := primitive.ParseDecimal128("12.50")
+	require.NoError(t, err)
+
+	value := map[string]interface{}{
+		"qty":   int32(3),
+		"big":   int64(3000000000),
+		"ratio": 0.5,
+		"price": price,
+		"tags":  []string{"a", "b"},
+	}
+
+	testCases := map[string]struct {
+		options mongorsql.NumericOptions
+		queries map[string]bool
+	}{
+		"Int64Width": {
+			mongorsql.NumericOptions{},
+			map[string]bool{`qty==3`: true, `tags=size=2`: true, `price==12.5`: true},
+		},
+		"Int32Width": {
+			mongorsql.NumericOptions{IntWidth: mongorsql.Int32Width},
+			map[string]bool{`qty==3`: true, `qty=in=(1,3)`: true, `qty=gt=3`: false, `tags=size=2`: true, `ratio=lt=1`: true},
+		},
+		"AutoIntWidth": {
+			mongorsql.NumericOptions{IntWidth: mongorsql.AutoIntWidth},
+			map[string]bool{`qty==3`: true, `big==3000000000`: true, `tags=size=2`: true, `tags=size=3`: false},
+		},
+		"Decimal": {
+			mongorsql.NumericOptions{Decimal: true},
+			map[string]bool{`ratio==0.5`: true, `price==12.5`: true, `price=gt=12.49`: true, `qty=lt=3.5`: true},
+		},
+		"DecimalLiteral": {
+			mongorsql.NumericOptions{},
+			map[string]bool{`price==$dec(12.50)`: true, `ratio=lt=$dec(0.6)`: true, `qty==$dec(4)`: false},
+		},
+		"Exponent": {
+			mongorsql.NumericOptions{Exponent: true},
+			map[string]bool{`big==3e9`: true, `ratio==5e-1`: true},
+		},
+		"Hex": {
+			mongorsql.NumericOptions{Hex: true},
+			map[string]bool{`qty==0x3`: true, `tags=size=0x2`: true},
+		},
+		"MixedTypes": {
+			mongorsql.NumericOptions{MixedTypes: true},
+			map[string]bool{`qty==3`: true, `qty!=3`: false, `price=in=(12.5)`: true},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name+"_Success", func(t *testing.T) {
+			t.Parallel()
+
+			for query, expected := range testCase.queries {
+				predicate, err := NewParser(nil).SetNumericOptions(testCase.options).Parse(query)
+				require.NoError(t, err, query)
+
+				actual, err := predicate(value)
+				require.NoError(t, err, query)
+				require.Equal(t, expected, actual, query)
+			}
+		})
+	}
+
+	t.Run("WithSmartParser_Success", func(t *testing.T) {
+		t.Parallel()
+
+		type doc struct {
+			Level int8                 `bson:"level"`
+			Price primitive.Decimal128 `bson:"price"`
+		}
+
+		parser, err := mongorsql.NewSmartParser(reflect.TypeOf(doc{}))
+		require.NoError(t, err)
+
+		node, err := parser.ParseAST(`level==3;price=ge=12`)
+		require.NoError(t, err)
+
+		predicate, err := Compile(node)
+		require.NoError(t, err)
+
+		actual, err := predicate(doc{Level: 3, Price: price})
+		require.NoError(t, err)
+		require.True(t, actual)
+	})
+}
+
+func TestPredicateFailCases(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithUnsupportedType_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		predicate, err := NewParser(nil).Parse(`a==1`)
+		require.NoError(t, err)
+
+		_, err = predicate(1)
+		require.Equal(t, UnsupportedTypeError{kind: "int"}, err)
+	})
+
+	t.Run("WithLimits_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).SetLimits(mongorsql.Limits{MaxComparisons: 1}).Parse(`a==1;b==2`)
+		require.Equal(t, errs.NewErrLimitExceeded(5, mongorsql.MaxComparisonsLimit, 1), err)
+	})
+
+	t.Run("WithFieldPolicies_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).SetFieldPolicies(mongorsql.FieldPolicies{"tenant_id": {Deny: true}}).Parse(`tenant_id==1`)
+		require.Equal(t, errs.NewErrFieldPolicyViolation(0, "tenant_id", "==", "field is not allowed"), err)
+	})
+
+	t.Run("WithCompatDialect_Success", func(t *testing.T) {
+		t.Parallel()
+
+		predicate, err := NewParser(nil).SetQueryDialect(mongorsql.CompatDialect).Parse(`name==John and age>18`)
+		require.NoError(t, err)
+		matches, err := predicate(map[string]interface{}{"name": "John", "age": 21})
+		require.NoError(t, err)
+		require.True(t, matches)
+
+		matches, err = predicate(map[string]interface{}{"name": "John", "age": 17})
+		require.NoError(t, err)
+		require.False(t, matches)
+	})
+
+	t.Run("WithSyntaxError_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(nil).Parse(`x=7`)
+		require.Equal(t, errs.NewErrUnexpectedToken(1, "="), err)
+	})
+
+	t.Run("WithDisallowedFieldNames_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser(tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name")).Parse(`age==1`)
+		require.Equal(t, errs.NewErrPolicyViolation("age"), err)
+	})
+}
@@ -0,0 +1,120 @@
+package rsql
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// resolve returns all values at given dotted path. Like MongoDB,
+// arrays on the path are traversed and every element is visited.
+// Existing null values are returned as invalid values.
+func resolve(value reflect.Value, path []string) []reflect.Value {
+	value = indirect(value)
+	if len(path) == 0 {
+		return []reflect.Value{value}
+	}
+
+	if !value.IsValid() {
+		return nil
+	}
+
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+
+		item := value.MapIndex(reflect.ValueOf(path[0]).Convert(value.Type().Key()))
+		if !item.IsValid() {
+			return nil
+		}
+
+		return resolve(item, path[1:])
+	case reflect.Struct:
+		field, found := structField(value, path[0])
+		if !found {
+			return nil
+		}
+
+		return resolve(field, path[1:])
+	case reflect.Array, reflect.Slice:
+		values := []reflect.Value{}
+
+		if index, err := strconv.Atoi(path[0]); err == nil && index >= 0 && index < value.Len() {
+			values = append(values, resolve(value.Index(index), path[1:])...)
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			values = append(values, resolve(value.Index(i), path)...)
+		}
+
+		return values
+	}
+
+	return nil
+}
+
+// structField returns the field of a struct with given name. The name is matched
+// against the `bson` tag, the `json` tag and the lowercase field name.
+func structField(value reflect.Value, name string) (reflect.Value, bool) {
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() || field.Tag.Get("bson") == "-" || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if fieldName(field) == name {
+			return value.Field(i), true
+		}
+
+		if field.Anonymous && tagName(field, "bson") == "" && tagName(field, "json") == "" {
+			if embedded := indirect(value.Field(i)); embedded.Kind() == reflect.Struct {
+				if item, found := structField(embedded, name); found {
+					return item, true
+				}
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// fieldName returns the name of a struct field, untagged fields
+// use the lowercase field name like the `bson` codec does.
+func fieldName(field reflect.StructField) string {
+	if name := tagName(field, "bson"); name != "" {
+		return name
+	}
+
+	if name := tagName(field, "json"); name != "" {
+		return name
+	}
+
+	return strings.ToLower(field.Name)
+}
+
+// tagName returns the name of a tag without options.
+func tagName(field reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// indirect dereferences pointers and interfaces.
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
@@ -2,7 +2,6 @@ package subset
 
 import (
 	"reflect"
-	"strings"
 
 	"github.com/StevenCyb/goapiutils/parser/errs"
 	"github.com/StevenCyb/goapiutils/parser/tokenizer"
@@ -17,14 +16,15 @@ const (
 	FieldNameType     tokenizer.Type = "FIELD_NAME"
 )
 
-// specialEncode is the map for encoding
-// a list of special characters.
+// specs of the tokenizer that are compiled once and shared by all parsers.
 //
 //nolint:gochecknoglobals
-var specialEncode = map[string]string{
-	`,`: "%5C%2C",
-	` `: "%20",
-	`=`: "%5C%3D",
+var specs = []*tokenizer.Spec{
+	tokenizer.NewSpec(`^\s+`, SkipType),
+	tokenizer.NewSpec(`^,`, JoinType),
+	tokenizer.NewSpec(`^\.`, PathSeparatorType),
+	tokenizer.NewSpec(`^=`, AssignmentType),
+	tokenizer.NewSpec(`^(?:[^\.,=\\]|\\.)*`, FieldNameType),
 }
 
 // NewParser creates a new parser.
@@ -32,14 +32,18 @@ func NewParser() *Parser {
 	return &Parser{}
 }
 
-// Parser provides the logic to parse rsql statements.
-type Parser struct {
+// Parser provides the logic to parse subset statements. The state of a
+// parse is kept per call, so a parser can be used by multiple goroutines.
+type Parser struct{}
+
+// state is the state of a single parse.
+type state struct {
 	tokenizer *tokenizer.Tokenizer
 	lookahead *tokenizer.Token
 }
 
 // eat return a token with expected type.
-func (p *Parser) eat(tokenType tokenizer.Type) (*tokenizer.Token, error) {
+func (p *state) eat(tokenType tokenizer.Type) (*tokenizer.Token, error) {
 	token := p.lookahead
 
 	if token == nil {
@@ -68,30 +72,27 @@ func (p *Parser) Parse(query string, fullObject interface{}) (interface{}, error
 		return fullObject, nil
 	}
 
-	for dec, enc := range specialEncode {
-		query = strings.ReplaceAll(query, enc, dec)
+	query, err = tokenizer.DecodeQuery(query)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
 	}
 
-	p.tokenizer = tokenizer.NewTokenizer(
-		query,
-		SkipType, SkipType,
-		[]*tokenizer.Spec{
-			tokenizer.NewSpec(`^\s+`, SkipType),
-			tokenizer.NewSpec(`^,`, JoinType),
-			tokenizer.NewSpec(`^\.`, PathSeparatorType),
-			tokenizer.NewSpec(`^=`, AssignmentType),
-			tokenizer.NewSpec(`^[^\.,=]*`, FieldNameType),
-		},
-		nil,
-	)
+	state := &state{
+		tokenizer: tokenizer.NewTokenizer(
+			query,
+			SkipType, SkipType,
+			specs,
+			nil,
+		),
+	}
 
-	p.lookahead, err = p.tokenizer.GetNextToken()
+	state.lookahead, err = state.tokenizer.GetNextToken()
 	if err != nil {
 		return nil, err //nolint:wrapcheck
 	}
 
 	subsetObject := map[string]interface{}{}
-	err = p.expression(reflect.ValueOf(fullObject), &subsetObject)
+	err = state.expression(reflect.ValueOf(fullObject), &subsetObject)
 
 	return subsetObject, err
 }
@@ -102,7 +103,7 @@ func (p *Parser) Parse(query string, fullObject interface{}) (interface{}, error
  * 	| <subset_spec> ',' <expression>
  * .
  */
-func (p *Parser) expression(object reflect.Value, subsetObject *map[string]interface{}) error {
+func (p *state) expression(object reflect.Value, subsetObject *map[string]interface{}) error {
 	err := p.subsetSpec(object, subsetObject)
 	if err != nil {
 		return err
@@ -126,7 +127,7 @@ func (p *Parser) expression(object reflect.Value, subsetObject *map[string]inter
  *	| <field_name> "." <subset_spec>
  * .
  */
-func (p *Parser) subsetSpec(object reflect.Value, subsetObject *map[string]interface{}) error {
+func (p *state) subsetSpec(object reflect.Value, subsetObject *map[string]interface{}) error {
 	var newObject reflect.Value
 
 	if p.lookahead == nil {
@@ -144,7 +145,7 @@ func (p *Parser) subsetSpec(object reflect.Value, subsetObject *map[string]inter
 
 	if object.Kind() == reflect.Map {
 		for _, key := range object.MapKeys() {
-			if key.String() == fieldNameToken.Value {
+			if key.String() == tokenizer.Unescape(fieldNameToken.Value) {
 				newObject = object.MapIndex(key)
 
 				break
@@ -180,7 +181,7 @@ func (p *Parser) subsetSpec(object reflect.Value, subsetObject *map[string]inter
 				return err
 			}
 
-			(*subsetObject)[newFieldNameToken.Value] = newObject.Interface()
+			(*subsetObject)[tokenizer.Unescape(newFieldNameToken.Value)] = newObject.Interface()
 
 			return nil
 		}
@@ -1,8 +1,10 @@
 package subset
 
 import (
+	"sync"
 	"testing"
 
+	"github.com/StevenCyb/goapiutils/parser/errs"
 	"github.com/stretchr/testify/require"
 )
 
@@ -127,3 +129,87 @@ func TestCombinedSubsetsMap(t *testing.T) {
 			map[string]interface{}{"extracted_a": 1})
 	})
 }
+
+func TestEscapedFieldNames(t *testing.T) {
+	t.Parallel()
+
+	var testObject interface{} = map[string]interface{}{
+		"a.b": 1, "c,d": 2, "e=f": 3, "g h": map[string]interface{}{"i": 4},
+	}
+
+	t.Run("BackslashEscapes_Success", func(t *testing.T) {
+		t.Parallel()
+
+		performTest(t,
+			`a\.b=ab,c\,d=c\,d,e\=f=e\=f`,
+			testObject,
+			map[string]interface{}{"ab": 1, "c,d": 2, "e=f": 3})
+	})
+
+	t.Run("PercentEncoding_Success", func(t *testing.T) {
+		t.Parallel()
+
+		performTest(t,
+			"g%20h.i=i,c%5C%2Cd=c%2520d",
+			testObject,
+			map[string]interface{}{"i": 4, "c%20d": 2})
+	})
+
+	t.Run("InvalidEncoding_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewParser().Parse("a%zz=a", testObject)
+		require.Equal(t, errs.NewErrInvalidEncoding(1, "%zz"), err)
+	})
+}
+
+func TestConcurrently(t *testing.T) {
+	t.Parallel()
+
+	parser := NewParser()
+	object := map[string]interface{}{"a": map[string]interface{}{"b": 1}, "c": "x"}
+	expected := map[string]interface{}{"b": 1, "d": "x"}
+
+	const goroutines = 16
+
+	errors := make([]error, goroutines)
+	results := make([]interface{}, goroutines)
+
+	var group sync.WaitGroup
+
+	for i := 0; i < goroutines; i++ {
+		group.Add(1)
+
+		go func(i int) {
+			defer group.Done()
+
+			for j := 0; j < 100; j++ {
+				results[i], errors[i] = parser.Parse(`a.b=b,c=d`, object)
+				if errors[i] != nil {
+					return
+				}
+			}
+		}(i)
+	}
+
+	group.Wait()
+
+	for i := 0; i < goroutines; i++ {
+		require.NoError(t, errors[i])
+		require.Equal(t, expected, results[i])
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	parser := NewParser()
+	object := map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": 2}, "d": "x"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.Parse(`a.b=b,a.c=c,d=d`, object); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package rsql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	mongorsql "github.com/StevenCyb/goapiutils/parser/mongo/rsql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dialect represent SQL dialect values.
+type Dialect byte
+
+const (
+	// PostgresDialect declare a dialect that uses
+	// `$1` placeholders and `"` quoted identifiers.
+	PostgresDialect Dialect = 0
+	// MySQLDialect declare a dialect that uses
+	// `?` placeholders and "`" quoted identifiers.
+	MySQLDialect Dialect = 1
+)
+
+// likeEscape is the escape character used for LIKE patterns.
+const likeEscape = "!"
+
+// identifier is the format of a single identifier segment.
+//
+//nolint:gochecknoglobals
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// likeReplacer escapes the wildcards of a LIKE pattern.
+//
+//nolint:gochecknoglobals
+var likeReplacer = strings.NewReplacer(
+	likeEscape, likeEscape+likeEscape,
+	"%", likeEscape+"%",
+	"_", likeEscape+"_",
+)
+
+// operators maps comparison operators to SQL operators.
+//
+//nolint:gochecknoglobals
+var operators = map[mongorsql.Operator]string{
+	mongorsql.EqualOperator:              "=",
+	mongorsql.NotEqualOperator:           "<>",
+	mongorsql.GreaterThanOperator:        ">",
+	mongorsql.GreaterThanOrEqualOperator: ">=",
+	mongorsql.LessThanOperator:           "<",
+	mongorsql.LessThanOrEqualOperator:    "<=",
+}
+
+// NewEmitter creates a new emitter for given SQL dialect.
+func NewEmitter(dialect Dialect) *Emitter {
+	return &Emitter{
+		dialect: dialect,
+	}
+}
+
+// Emitter converts an abstract syntax tree into a SQL WHERE clause.
+type Emitter struct {
+	dialect Dialect
+}
+
+// Emit converts a given node into a WHERE clause with bind placeholders
+// and the related arguments. A nil node results in an empty clause.
+func (e *Emitter) Emit(node mongorsql.Node) (string, []interface{}, error) {
+	statement := &statement{dialect: e.dialect, args: []interface{}{}}
+
+	if node == nil {
+		return "", statement.args, nil
+	}
+
+	clause, err := statement.node(node)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return clause, statement.args, nil
+}
+
+// statement holds the state of a single emit call.
+type statement struct {
+	args    []interface{}
+	dialect Dialect
+}
+
+// bind adds an argument and returns the related placeholder.
+func (s *statement) bind(value interface{}) string {
+	s.args = append(s.args, value)
+
+	if s.dialect == MySQLDialect {
+		return "?"
+	}
+
+	return "$" + strconv.Itoa(len(s.args))
+}
+
+// identifier quotes a dotted field name.
+func (s *statement) identifier(name string) (string, error) {
+	quote := `"`
+	if s.dialect == MySQLDialect {
+		quote = "`"
+	}
+
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		if !identifier.MatchString(segment) {
+			return "", InvalidIdentifierError{name: name}
+		}
+
+		segments[i] = quote + segment + quote
+	}
+
+	return strings.Join(segments, "."), nil
+}
+
+// node converts a node into a clause.
+func (s *statement) node(node mongorsql.Node) (string, error) {
+	switch node := node.(type) {
+	case *mongorsql.AndNode:
+		return s.composite(" AND ", node.Children)
+	case *mongorsql.OrNode:
+		return s.composite(" OR ", node.Children)
+	case *mongorsql.GroupNode:
+		return s.node(node.Child)
+	case *mongorsql.NotNode:
+		clause, err := s.node(node.Child)
+		if err != nil {
+			return "", err
+		}
+
+		return "NOT (" + clause + ")", nil
+	case *mongorsql.ComparisonNode:
+		return s.comparison(node)
+	case *mongorsql.ElemMatchNode:
+		return "", UnsupportedOperatorError{operator: string(mongorsql.ElemMatchOperator), position: node.Position}
+	}
+
+	return "", errs.NewErrUnexpectedInput(node)
+}
+
+// composite joins the children with given logical operator.
+func (s *statement) composite(operator string, children []mongorsql.Node) (string, error) {
+	clauses := make([]string, 0, len(children))
+
+	for _, child := range children {
+		clause, err := s.node(child)
+		if err != nil {
+			return "", err
+		}
+
+		clauses = append(clauses, clause)
+	}
+
+	return "(" + strings.Join(clauses, operator) + ")", nil
+}
+
+// comparison converts a comparison node into a clause.
+func (s *statement) comparison(node *mongorsql.ComparisonNode) (string, error) {
+	column, err := s.identifier(node.Field)
+	if err != nil {
+		return "", err
+	}
+
+	switch node.Operator {
+	case mongorsql.NullOperator:
+		if node.Argument.Value == true {
+			return column + " IS NULL", nil
+		}
+
+		return column + " IS NOT NULL", nil
+	case mongorsql.EqualOperator, mongorsql.NotEqualOperator:
+		if node.Argument.Kind != mongorsql.NullLiteralKind {
+			break
+		}
+
+		if node.Operator == mongorsql.EqualOperator {
+			return column + " IS NULL", nil
+		}
+
+		return column + " IS NOT NULL", nil
+	case mongorsql.InOperator, mongorsql.NotInOperator:
+		return s.list(column, node)
+	case mongorsql.StartsWithOperator, mongorsql.EndsWithOperator, mongorsql.LikeOperator,
+		mongorsql.ContainsOperator, mongorsql.CaseInsensitiveStartsWithOperator,
+		mongorsql.CaseInsensitiveEndsWithOperator, mongorsql.CaseInsensitiveEqualOperator:
+		return s.like(column, node)
+	}
+
+	operator, ok := operators[node.Operator]
+	if !ok {
+		return "", UnsupportedOperatorError{operator: string(node.Operator), position: node.Position}
+	}
+
+	value, err := s.value(node.Argument)
+	if err != nil {
+		return "", err
+	}
+
+	return column + " " + operator + " " + s.bind(value), nil
+}
+
+// list converts a list comparison into an IN clause.
+func (s *statement) list(column string, node *mongorsql.ComparisonNode) (string, error) {
+	items, ok := node.Argument.Value.([]mongorsql.Literal)
+	if !ok || len(items) == 0 {
+		return "", UnsupportedLiteralError{kind: string(node.Argument.Kind), position: node.Argument.Position}
+	}
+
+	placeholders := make([]string, 0, len(items))
+
+	for _, item := range items {
+		value, err := s.value(item)
+		if err != nil {
+			return "", err
+		}
+
+		placeholders = append(placeholders, s.bind(value))
+	}
+
+	operator := " IN ("
+	if node.Operator == mongorsql.NotInOperator {
+		operator = " NOT IN ("
+	}
+
+	return column + operator + strings.Join(placeholders, ", ") + ")", nil
+}
+
+// like converts a string comparison into a LIKE clause.
+func (s *statement) like(column string, node *mongorsql.ComparisonNode) (string, error) {
+	value, ok := node.Argument.Value.(string)
+	if !ok {
+		return "", UnsupportedLiteralError{kind: string(node.Argument.Kind), position: node.Argument.Position}
+	}
+
+	pattern := likeReplacer.Replace(value)
+	caseInsensitive := false
+
+	switch node.Operator { //nolint:exhaustive
+	case mongorsql.StartsWithOperator:
+		pattern += "%"
+	case mongorsql.EndsWithOperator:
+		pattern = "%" + pattern
+	case mongorsql.ContainsOperator:
+		pattern = "%" + pattern + "%"
+	case mongorsql.LikeOperator:
+		parts := strings.Split(value, "*")
+		for i, part := range parts {
+			parts[i] = likeReplacer.Replace(part)
+		}
+
+		pattern = strings.Join(parts, "%")
+	case mongorsql.CaseInsensitiveStartsWithOperator:
+		pattern += "%"
+		caseInsensitive = true
+	case mongorsql.CaseInsensitiveEndsWithOperator:
+		pattern = "%" + pattern
+		caseInsensitive = true
+	case mongorsql.CaseInsensitiveEqualOperator:
+		caseInsensitive = true
+	}
+
+	escape := " ESCAPE '" + likeEscape + "'"
+
+	switch {
+	case !caseInsensitive:
+		return column + " LIKE " + s.bind(pattern) + escape, nil
+	case s.dialect == MySQLDialect:
+		return "LOWER(" + column + ") LIKE LOWER(" + s.bind(pattern) + ")" + escape, nil
+	default:
+		return column + " ILIKE " + s.bind(pattern) + escape, nil
+	}
+}
+
+// value converts a literal into a bind argument.
+func (s *statement) value(literal mongorsql.Literal) (interface{}, error) {
+	switch value := literal.Value.(type) {
+	case primitive.ObjectID:
+		return value.Hex(), nil
+	case int32:
+		return int64(value), nil
+	case primitive.Decimal128:
+		// drivers accept exact decimals as strings, a float64 would lose precision
+		return value.String(), nil
+	case bool, string, int64, float64, time.Time:
+		return value, nil
+	}
+
+	return nil, UnsupportedLiteralError{kind: string(literal.Kind), position: literal.Position}
+}
@@ -0,0 +1,34 @@
+package rsql
+
+import (
+	"fmt"
+)
+
+// UnsupportedOperatorError indicate that an operator can not be expressed in SQL.
+type UnsupportedOperatorError struct {
+	operator string
+	position int
+}
+
+func (u UnsupportedOperatorError) Error() string {
+	return fmt.Sprintf("operator '%s' at position '%d' is not supported", u.operator, u.position)
+}
+
+// UnsupportedLiteralError indicate that a literal can not be used as bind argument.
+type UnsupportedLiteralError struct {
+	kind     string
+	position int
+}
+
+func (u UnsupportedLiteralError) Error() string {
+	return fmt.Sprintf("literal of kind '%s' at position '%d' is not supported", u.kind, u.position)
+}
+
+// InvalidIdentifierError indicate that a field name is not a valid SQL identifier.
+type InvalidIdentifierError struct {
+	name string
+}
+
+func (i InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("field name '%s' is not a valid identifier", i.name)
+}
@@ -0,0 +1,28 @@
+package rsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsupportedOperatorError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "operator '=x=' at position '3' is not supported",
+		UnsupportedOperatorError{operator: "=x=", position: 3}.Error())
+}
+
+func TestUnsupportedLiteralError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "literal of kind 'LIST' at position '3' is not supported",
+		UnsupportedLiteralError{kind: "LIST", position: 3}.Error())
+}
+
+func TestInvalidIdentifierError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "field name 'a b' is not a valid identifier",
+		InvalidIdentifierError{name: "a b"}.Error())
+}
@@ -0,0 +1,60 @@
+package rsql
+
+import (
+	mongorsql "github.com/StevenCyb/goapiutils/parser/mongo/rsql"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+)
+
+// NewParser creates a new parser for given SQL dialect.
+func NewParser(dialect Dialect, policy *tokenizer.Policy) *Parser {
+	return &Parser{
+		parser:  mongorsql.NewParser(policy),
+		emitter: NewEmitter(dialect),
+	}
+}
+
+// Parser provides the logic to parse rsql
+// statements into SQL WHERE clauses.
+type Parser struct {
+	parser  *mongorsql.Parser
+	emitter *Emitter
+}
+
+// SetQueryDialect sets the syntax of the queries, the default is `mongorsql.NativeDialect`.
+func (p *Parser) SetQueryDialect(dialect mongorsql.Dialect) *Parser {
+	p.parser.SetDialect(dialect)
+
+	return p
+}
+
+// SetFieldPolicies sets policies that restrict the operators and values of fields.
+func (p *Parser) SetFieldPolicies(policies mongorsql.FieldPolicies) *Parser {
+	p.parser.SetFieldPolicies(policies)
+
+	return p
+}
+
+// SetNumericOptions sets the options of numeric literals.
+func (p *Parser) SetNumericOptions(options mongorsql.NumericOptions) *Parser {
+	p.parser.SetNumericOptions(options)
+
+	return p
+}
+
+// SetLimits sets limits that restrict the complexity of queries.
+func (p *Parser) SetLimits(limits mongorsql.Limits) *Parser {
+	p.parser.SetLimits(limits)
+
+	return p
+}
+
+// Parse a given query into a WHERE clause with bind placeholders
+// and the related arguments. An empty query results in an empty clause.
+func (p *Parser) Parse(query string) (string, []interface{}, error) {
+	node, err := p.parser.ParseAST(query)
+	if err != nil {
+		return "", nil, err //nolint:wrapcheck
+	}
+
+	return p.emitter.Emit(node)
+}
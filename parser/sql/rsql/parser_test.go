@@ -0,0 +1,342 @@
+//nolint:funlen
+package rsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	mongorsql "github.com/StevenCyb/goapiutils/parser/mongo/rsql"
+	"github.com/StevenCyb/goapiutils/parser/tokenizer"
+	"github.com/stretchr/testify/require"
+)
+
+func executeSuccessTest(t *testing.T, parser *Parser, query, expectedClause string, expectedArgs ...interface{}) {
+	t.Helper()
+
+	clause, args, err := parser.Parse(query)
+	require.NoError(t, err)
+	require.Equal(t, expectedClause, clause)
+
+	if expectedArgs == nil {
+		expectedArgs = []interface{}{}
+	}
+
+	require.Equal(t, expectedArgs, args)
+}
+
+func executeFailedTest(t *testing.T, parser *Parser, query string, expectedError error) {
+	t.Helper()
+
+	_, _, err := parser.Parse(query)
+	require.Equal(t, expectedError, err)
+}
+
+func TestQueryParsingWithEmptyQuery_Success(t *testing.T) {
+	t.Parallel()
+
+	executeSuccessTest(t, NewParser(PostgresDialect, nil), "", "")
+}
+
+func TestQueryParsingWithSingleComparisonOperation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("==_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`first_name=="steven"`, `"first_name" = $1`, "steven")
+	})
+
+	t.Run("==OID_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`id==$oid(01234567890abcdef1234567)`, `"id" = $1`, "01234567890abcdef1234567")
+	})
+
+	t.Run("=gt=Date_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`created_at=gt=$date(2024-01-01)`, `"created_at" > $1`, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	})
+
+	t.Run("!=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`active!=true`, `"active" <> $1`, true)
+	})
+
+	t.Run("=gt=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`x=gt=10`, `"x" > $1`, int64(10))
+	})
+
+	t.Run("=ge=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`x=ge=10`, `"x" >= $1`, int64(10))
+	})
+
+	t.Run("=lt=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`x=lt=1.5`, `"x" < $1`, 1.5)
+	})
+
+	t.Run("=le=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`x=le=10`, `"x" <= $1`, int64(10))
+	})
+
+	t.Run("=gt=Int32_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil).SetNumericOptions(mongorsql.NumericOptions{
+			IntWidth: mongorsql.Int32Width,
+		}), `x=gt=10`, `"x" > $1`, int64(10))
+	})
+
+	t.Run("==Decimal_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`price==$dec(12.50)`, `"price" = $1`, "12.50")
+		executeSuccessTest(t, NewParser(PostgresDialect, nil).SetNumericOptions(mongorsql.NumericOptions{Decimal: true}),
+			`price=in=(1.5,2)`, `"price" IN ($1, $2)`, "1.5", int64(2))
+	})
+
+	t.Run("=sw=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`msg=sw="100%25_!"`, `"msg" LIKE $1 ESCAPE '!'`, "100!%!_!!%")
+	})
+
+	t.Run("=ew=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`file=ew=".jpg"`, `"file" LIKE $1 ESCAPE '!'`, "%.jpg")
+	})
+
+	t.Run("=like=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`file=like="img_*.jpg"`, `"file" LIKE $1 ESCAPE '!'`, "img!_%.jpg")
+	})
+
+	t.Run("=contains=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`title=contains="go"`, `"title" LIKE $1 ESCAPE '!'`, "%go%")
+	})
+
+	t.Run("=isw=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`name=isw="st"`, `"name" ILIKE $1 ESCAPE '!'`, "st%")
+	})
+
+	t.Run("=iew=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`name=iew="en"`, `"name" ILIKE $1 ESCAPE '!'`, "%en")
+	})
+
+	t.Run("=ieq=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`name=ieq="a_b"`, `"name" ILIKE $1 ESCAPE '!'`, "a!_b")
+	})
+
+	t.Run("==null_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`deleted_at==null`, `"deleted_at" IS NULL`)
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`deleted_at!=null`, `"deleted_at" IS NOT NULL`)
+	})
+
+	t.Run("=null=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`deleted_at=null=true`, `"deleted_at" IS NULL`)
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`deleted_at=null=false`, `"deleted_at" IS NOT NULL`)
+	})
+
+	t.Run("=in=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`coll=in=(1, "a")`, `"coll" IN ($1, $2)`, int64(1), "a")
+	})
+
+	t.Run("=out=_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`coll=out=(1, "a")`, `"coll" NOT IN ($1, $2)`, int64(1), "a")
+	})
+}
+
+func TestQueryParsingWithMultipleComparisonOperation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithMixed_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`a==1;b==1,a==2;b==2`,
+			`("a" = $1 AND ("b" = $2 OR ("a" = $3 AND "b" = $4)))`,
+			int64(1), int64(1), int64(2), int64(2))
+	})
+
+	t.Run("WithNestedContext_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`(a==1;b==1),user.age=ge=18`,
+			`(("a" = $1 AND "b" = $2) OR "user"."age" >= $3)`,
+			int64(1), int64(1), int64(18))
+	})
+
+	t.Run("WithNegation_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil),
+			`!(status=="a";age=lt=18),!b==1`,
+			`(NOT (("status" = $1 AND "age" < $2)) OR NOT ("b" = $3))`,
+			"a", int64(18), int64(1))
+	})
+}
+
+func TestQueryParsingWithMySQLDialect(t *testing.T) {
+	t.Parallel()
+
+	executeSuccessTest(t, NewParser(MySQLDialect, nil),
+		`name=="steven";age=in=(18,19)`,
+		"(`name` = ? AND `age` IN (?, ?))",
+		"steven", int64(18), int64(19))
+
+	executeSuccessTest(t, NewParser(MySQLDialect, nil),
+		`name=ieq="Steven"`,
+		"LOWER(`name`) LIKE LOWER(?) ESCAPE '!'",
+		"Steven")
+}
+
+func TestQueryParsingFailCases(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithInvalidIdentifier_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil),
+			`a"; DROP TABLE x; --==1`,
+			InvalidIdentifierError{name: `a"; DROP TABLE x; --`})
+	})
+
+	t.Run("WithArrayEqual_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil),
+			`roles==("dev","admin")`,
+			UnsupportedLiteralError{kind: "LIST", position: 7})
+	})
+
+	t.Run("WithExists_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil),
+			`deleted_at=exists=true`,
+			UnsupportedOperatorError{operator: "=exists=", position: 0})
+	})
+
+	t.Run("WithElemMatch_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil),
+			`items=em=(sku=="a")`,
+			UnsupportedOperatorError{operator: "=em=", position: 0})
+	})
+
+	t.Run("WithNullInList_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil),
+			`status=in=("a",null)`,
+			UnsupportedLiteralError{kind: "NULL", position: 15})
+	})
+
+	t.Run("WithLimits_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil).SetLimits(mongorsql.Limits{MaxListLength: 1}),
+			`a=in=(1,2)`,
+			errs.NewErrLimitExceeded(8, mongorsql.MaxListLengthLimit, 1))
+	})
+
+	t.Run("WithFieldPolicies_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil).SetFieldPolicies(mongorsql.FieldPolicies{
+			"tenant_id": {Deny: true},
+		}),
+			`name=="x";tenant_id==1`,
+			errs.NewErrFieldPolicyViolation(10, "tenant_id", "==", "field is not allowed"))
+	})
+
+	t.Run("WithCompatDialect_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t, NewParser(PostgresDialect, nil).SetQueryDialect(mongorsql.CompatDialect),
+			`name==John and age>18`, `("name" = $1 AND "age" > $2)`, "John", int64(18))
+	})
+
+	t.Run("WithSyntaxError_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t, NewParser(PostgresDialect, nil),
+			`x=7`,
+			errs.NewErrUnexpectedToken(1, "="))
+	})
+}
+
+func TestQueryParsingWithPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithAllowedFieldName_Success", func(t *testing.T) {
+		t.Parallel()
+
+		executeSuccessTest(t,
+			NewParser(PostgresDialect, tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name", "age")),
+			`name=="steven",age=ge=18`,
+			`("name" = $1 OR "age" >= $2)`,
+			"steven", int64(18))
+	})
+
+	t.Run("WithDisallowedFieldNames_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		executeFailedTest(t,
+			NewParser(PostgresDialect, tokenizer.NewPolicy(tokenizer.WhitelistPolicy, "name", "age")),
+			`name=="steven",gender=="male"`,
+			errs.NewErrPolicyViolation("gender"))
+	})
+}
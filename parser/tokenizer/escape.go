@@ -0,0 +1,81 @@
+package tokenizer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+)
+
+// urlReserved are the characters that `Escape` percent-encodes.
+const urlReserved = "%&#+ "
+
+// DecodeQuery percent-decodes a query once as defined by RFC 3986.
+// Unlike form decoding a `+` is kept, so that signs of numbers and
+// time zones don't have to be encoded.
+func DecodeQuery(query string) (string, error) {
+	decoded, err := url.PathUnescape(query)
+	if err != nil {
+		var escapeErr url.EscapeError
+		if errors.As(err, &escapeErr) {
+			return "", errs.NewErrInvalidEncoding(strings.Index(query, string(escapeErr)), string(escapeErr))
+		}
+
+		return "", err //nolint:wrapcheck
+	}
+
+	return decoded, nil
+}
+
+// Unescape removes the backslash of escape sequences, e.g. `\,` becomes `,`.
+func Unescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+
+		builder.WriteByte(value[i])
+	}
+
+	return builder.String()
+}
+
+// Unquote removes the surrounding quotes of a
+// quoted literal and unescapes its content.
+func Unquote(literal string) string {
+	if len(literal) < 2 { //nolint:gomnd
+		return literal
+	}
+
+	return Unescape(literal[1 : len(literal)-1])
+}
+
+// Escape prefixes backslashes and given special characters with a backslash
+// and percent-encodes `%` and the characters that are reserved in URL queries
+// (`&`, `#`, `+` and space), so that `DecodeQuery` and `Unescape` restore the value.
+func Escape(value, special string) string {
+	var builder strings.Builder
+
+	for _, char := range value {
+		switch {
+		case strings.ContainsRune(urlReserved, char):
+			builder.WriteString(fmt.Sprintf("%%%02X", char))
+
+			continue
+		case char == '\\' || strings.ContainsRune(special, char):
+			builder.WriteByte('\\')
+		}
+
+		builder.WriteRune(char)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,64 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeQuery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Decode_Success", func(t *testing.T) {
+		t.Parallel()
+
+		for query, expected := range map[string]string{
+			`a=="b"`:              `a=="b"`,
+			`a%3D%3D%22b%20c%22`:  `a=="b c"`,
+			`a=="%5C%2C"`:         `a=="\,"`,
+			`a=="%25%2525"`:       `a=="%%25"`,
+			`a==+1;b==2024+01:00`: `a==+1;b==2024+01:00`,
+			`name=="M%C3%BCller"`: `name=="Müller"`,
+		} {
+			actual, err := DecodeQuery(query)
+			require.NoError(t, err, query)
+			require.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("InvalidSequence_Fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DecodeQuery(`a=="100%"`)
+		require.Equal(t, errs.NewErrInvalidEncoding(7, "%\""), err)
+
+		_, err = DecodeQuery(`a=="%zz"`)
+		require.Equal(t, errs.NewErrInvalidEncoding(4, "%zz"), err)
+	})
+}
+
+func TestUnescape(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "abc", Unescape("abc"))
+	require.Equal(t, `a,b=c"d\e`, Unescape(`a\,b\=c\"d\\e`))
+	require.Equal(t, `a\`, Unescape(`a\`))
+	require.Equal(t, `it's "x"`, Unquote(`'it\'s "x"'`))
+	require.Equal(t, `say "hi"`, Unquote(`"say \"hi\""`))
+	require.Equal(t, "", Unquote(`""`))
+}
+
+func TestEscape(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `a\,b\=c\\d%25e%20f`, Escape(`a,b=c\d%e f`, ",="))
+	require.Equal(t, `say%20\"hi\"`, Escape(`say "hi"`, `"`))
+	require.Equal(t, `a%26b%23c%2Bd`, Escape(`a&b#c+d`, ""))
+
+	for _, value := range []string{`a,b`, `\\`, `100%`, `%20`, `"'`, `Müller`, `a & b`, `#1+1`} {
+		decoded, err := DecodeQuery(Escape(value, `,"`))
+		require.NoError(t, err)
+		require.Equal(t, value, Unescape(decoded))
+	}
+}
@@ -0,0 +1,35 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+)
+
+// Reasons why a field name is invalid.
+const (
+	EmptySegmentReason     = "empty segment"
+	OperatorSegmentReason  = "segment starts with \"$\""
+	ControlCharacterReason = "contains control character"
+)
+
+// ValidateFieldName checks that a dotted field name has no empty segments,
+// no segments starting with `$` and no control characters.
+func ValidateFieldName(name string, position int) error {
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return errs.NewErrInvalidFieldName(position, name, ControlCharacterReason)
+	}
+
+	for _, segment := range strings.Split(name, ".") {
+		if segment == "" {
+			return errs.NewErrInvalidFieldName(position, name, EmptySegmentReason)
+		}
+
+		if strings.HasPrefix(segment, "$") {
+			return errs.NewErrInvalidFieldName(position, name, OperatorSegmentReason)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/StevenCyb/goapiutils/parser/errs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFieldName(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateFieldName("a", 0))
+	require.NoError(t, ValidateFieldName("_id", 0))
+	require.NoError(t, ValidateFieldName("user.address.city", 0))
+	require.NoError(t, ValidateFieldName("price$", 0))
+
+	require.Equal(t, errs.NewErrInvalidFieldName(3, "$where", OperatorSegmentReason),
+		ValidateFieldName("$where", 3))
+	require.Equal(t, errs.NewErrInvalidFieldName(0, "user.$expr", OperatorSegmentReason),
+		ValidateFieldName("user.$expr", 0))
+	require.Equal(t, errs.NewErrInvalidFieldName(0, "a..b", EmptySegmentReason),
+		ValidateFieldName("a..b", 0))
+	require.Equal(t, errs.NewErrInvalidFieldName(0, ".a", EmptySegmentReason),
+		ValidateFieldName(".a", 0))
+	require.Equal(t, errs.NewErrInvalidFieldName(0, "a\x00b", ControlCharacterReason),
+		ValidateFieldName("a\x00b", 0))
+}
@@ -10,8 +10,9 @@ func (t Type) String() string {
 
 // Token represents a single token.
 type Token struct {
-	Type  Type
-	Value string
+	Type     Type
+	Value    string
+	Position int
 }
 
 // NewToken creates a new token with given arguments.
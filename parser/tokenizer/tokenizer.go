@@ -38,7 +38,9 @@ func (t *Tokenizer) GetNextToken() (*Token, error) {
 			continue
 		}
 
+		position := t.cursor
 		t.cursor += len(matched)
+
 		if spec.tokenType == t.skipTokenType {
 			return t.GetNextToken()
 		}
@@ -47,10 +49,13 @@ func (t *Tokenizer) GetNextToken() (*Token, error) {
 			return nil, errs.NewErrPolicyViolation(matched)
 		}
 
-		return NewToken(
+		token := NewToken(
 			spec.tokenType,
 			matched,
-		), nil
+		)
+		token.Position = position
+
+		return token, nil
 	}
 
 	return nil, errs.NewErrUnexpectedToken(
@@ -79,6 +79,27 @@ func TestTokenizer(t *testing.T) {
 		require.Equal(t, value, token.Value)
 	})
 
+	t.Run("TokenPosition", func(t *testing.T) {
+		t.Parallel()
+
+		var SkipType Type = "SKIP"
+		tokenizer := NewTokenizer(
+			`  hello  = world `,
+			SkipType, NoneType,
+			[]*Spec{
+				NewSpec(`^\s+`, SkipType),
+				NewSpec("^=", EqualType),
+				NewSpec("^[a-z]+", WordType),
+			},
+			nil)
+
+		for _, expected := range []int{2, 9, 11} {
+			token, err := tokenizer.GetNextToken()
+			require.NoError(t, err)
+			require.Equal(t, expected, token.Position)
+		}
+	})
+
 	t.Run("PolicyWithoutViolation", func(t *testing.T) {
 		t.Parallel()
 